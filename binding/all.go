@@ -0,0 +1,162 @@
+package binding
+
+import (
+	"errors"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"reflect"
+)
+
+// bodyDecoder returns the decoder of the request body of b, without running
+// the validation, when b is a binding of this package reading the body. The
+// bindings are matched by type, as custom bindings may not be comparable.
+func bodyDecoder(b Binding) (func(io.Reader, interface{}) error, bool) {
+	switch b.(type) {
+	case jsonBinding:
+		return decodeJSON, true
+	case xmlBinding:
+		return decodeXML, true
+	case yamlBinding:
+		return decodeYAML, true
+	case tomlBinding:
+		return decodeToml, true
+	case msgpackBinding:
+		return decodeMsgPack, true
+	case protobufBinding:
+		return func(r io.Reader, obj interface{}) error {
+			buf, err := ioutil.ReadAll(r)
+			if err != nil {
+				return err
+			}
+			return ProtoBuf.BindBody(buf, obj)
+		}, true
+	}
+	return nil, false
+}
+
+// BindAll fills obj from every source of the request and then validates it
+// exactly once. Sources are applied from the lowest to the highest precedence,
+// a value found in a later source overwrites the one found in an earlier source:
+//
+//	body (selected by Content-Type) < form < query < header < uri
+//
+// The body is decoded with the json/xml/yaml/... tags, the form and query with
+// the "form" tag, the header with the "header" tag and the uri params with the
+// "uri" tag. A "default" tag option is only applied to fields left untouched
+// by all sources.
+func BindAll(req *http.Request, uri map[string][]string, obj interface{}) error {
+	if reflect.TypeOf(obj).Kind() != reflect.Ptr {
+		return errors.New("obj must be a pointer")
+	}
+
+	contentType := filterFlags(req.Header.Get("Content-Type"))
+	var bodySource string
+	if body := Default(req.Method, contentType); req.Body != nil && req.Body != http.NoBody {
+		if decode, ok := bodyDecoder(body); ok {
+			if err := decode(req.Body, obj); err != nil && err != io.EOF {
+				return err
+			}
+			bodySource = body.Name()
+		}
+	}
+
+	filled := make(map[uintptr]string)
+	var form setter
+	switch contentType {
+	case MIMEMultipartPOSTForm:
+		if err := req.ParseMultipartForm(defaultMemory); err != nil {
+			return err
+		}
+		form = (*multipartRequest)(req)
+	case MIMEPOSTForm:
+		if err := req.ParseForm(); err != nil {
+			return err
+		}
+		form = formSource(req.PostForm)
+	}
+
+	sources := []struct {
+		setter setter
+		tag    string
+		source string
+	}{
+		{form, "form", "form"},
+		{formSource(req.URL.Query()), "form", "query"},
+		{headerSource(req.Header), "header", "header"},
+		{formSource(uri), "uri", "uri"},
+	}
+	for _, src := range sources {
+		if src.setter == nil {
+			continue
+		}
+		err := mappingByPtr(obj, trackingSetter{src.setter, src.source, filled}, src.tag)
+		if err != nil {
+			return withSource(err, src.source)
+		}
+	}
+	for _, tag := range []string{"form", "header", "uri"} {
+		if err := mappingByPtr(obj, defaultSetter(filled), tag); err != nil {
+			return err
+		}
+	}
+
+	err := validate(req.Context(), obj, "")
+	if errs, ok := err.(Errors); ok {
+		// tell which source the failing values came from
+		root := reflect.ValueOf(obj)
+		for _, e := range errs {
+			addr, ok := lookupFieldAddr(root, e.Field)
+			if !ok {
+				continue
+			}
+			if e.Source, ok = filled[addr]; !ok && e.Value != "" && bodySource != "" {
+				e.Source = bodySource
+			}
+			if sf, ok := lookupField(root, e.Field); ok && e.Source != "" {
+				e.Key = fieldKey(sf, e.Source)
+			}
+		}
+	}
+	return err
+}
+
+// trackingSetter records the fields filled by the wrapped setter and never
+// applies the default values, see BindAll.
+type trackingSetter struct {
+	setter
+	source string
+	filled map[uintptr]string
+}
+
+func (s trackingSetter) TrySet(value reflect.Value, field reflect.StructField, key string, opt setOptions) (bool, error) {
+	opt.isDefaultExists = false
+	isSet, err := s.setter.TrySet(value, field, key, opt)
+	if isSet && value.CanAddr() {
+		s.filled[value.UnsafeAddr()] = s.source
+	}
+	return isSet, err
+}
+
+// defaultSetter applies the default values to the fields which were neither
+// filled by a trackingSetter nor decoded from the body.
+type defaultSetter map[uintptr]string
+
+func (filled defaultSetter) TrySet(value reflect.Value, field reflect.StructField, key string, opt setOptions) (bool, error) {
+	if !opt.isDefaultExists || !value.CanAddr() || !value.IsZero() {
+		return false, nil
+	}
+	if _, ok := filled[value.UnsafeAddr()]; ok {
+		return false, nil
+	}
+	return setByForm(value, field, nil, key, opt)
+}
+
+func filterFlags(content string) string {
+	for i, char := range content {
+		if char == ' ' || char == ';' {
+			return content[:i]
+		}
+	}
+	return content
+}
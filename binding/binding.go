@@ -4,7 +4,10 @@
 
 package binding
 
-import "net/http"
+import (
+	"context"
+	"net/http"
+)
 
 // Content-Type MIME of the most common data formats.
 const (
@@ -13,6 +16,13 @@ const (
 	MIMEPlain             = "text/plain"
 	MIMEPOSTForm          = "application/x-www-form-urlencoded"
 	MIMEMultipartPOSTForm = "multipart/form-data"
+	MIMEXML               = "application/xml"
+	MIMEXML2              = "text/xml"
+	MIMEPROTOBUF          = "application/x-protobuf"
+	MIMEMSGPACK           = "application/x-msgpack"
+	MIMEMSGPACK2          = "application/msgpack"
+	MIMEYAML              = "application/x-yaml"
+	MIMETOML              = "application/toml"
 )
 
 // Binding describes the interface which needs to be implemented for binding the
@@ -55,6 +65,16 @@ type StructValidator interface {
 	Engine() interface{}
 }
 
+// ContextValidator is implemented by the validators able to pass the context
+// of the request to the validation functions, see RegisterValidationCtx.
+type ContextValidator interface {
+	StructValidator
+
+	// ValidateStructContext is like ValidateStruct, the bindings reading
+	// a request pass its context as ctx.
+	ValidateStructContext(ctx context.Context, obj interface{}) error
+}
+
 // Validator is the default validator which implements the StructValidator
 // interface. It uses https://github.com/go-playground/validator/tree/v10.6.1
 // under the hood.
@@ -70,6 +90,11 @@ var (
 	Form          = formBinding{}
 	FormPost      = formPostBinding{}
 	FormMultipart = formMultipartBinding{}
+	XML           = xmlBinding{}
+	YAML          = yamlBinding{}
+	TOML          = tomlBinding{}
+	MsgPack       = msgpackBinding{}
+	ProtoBuf      = protobufBinding{}
 )
 
 // Default returns the appropriate Binding instance based on the HTTP method
@@ -82,16 +107,19 @@ func Default(method, contentType string) Binding {
 	switch contentType {
 	case MIMEJSON:
 		return JSON
+	case MIMEXML, MIMEXML2:
+		return XML
+	case MIMEPROTOBUF:
+		return ProtoBuf
+	case MIMEMSGPACK, MIMEMSGPACK2:
+		return MsgPack
+	case MIMEYAML:
+		return YAML
+	case MIMETOML:
+		return TOML
 	case MIMEMultipartPOSTForm:
 		return FormMultipart
 	default:
 		return Form
 	}
 }
-
-func validate(obj interface{}) error {
-	if Validator == nil {
-		return nil
-	}
-	return Validator.ValidateStruct(obj)
-}
@@ -11,10 +11,13 @@ import (
 	"testing"
 
 	"github.com/stretchr/testify/assert"
+	"github.com/ugorji/go/codec"
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/types/known/wrapperspb"
 )
 
 type FooStruct struct {
-	Foo string `msgpack:"foo" json:"foo" form:"foo" xml:"foo" binding:"required,max=32"`
+	Foo string `msgpack:"foo" json:"foo" form:"foo" xml:"foo" yaml:"foo" toml:"foo" binding:"required,max=32"`
 }
 
 type FooStructUseNumber struct {
@@ -34,6 +37,21 @@ func TestBindingDefault(t *testing.T) {
 
 	assert.Equal(t, FormMultipart, Default("POST", MIMEMultipartPOSTForm))
 	assert.Equal(t, FormMultipart, Default("PUT", MIMEMultipartPOSTForm))
+
+	assert.Equal(t, XML, Default("POST", MIMEXML))
+	assert.Equal(t, XML, Default("PUT", MIMEXML2))
+
+	assert.Equal(t, ProtoBuf, Default("POST", MIMEPROTOBUF))
+	assert.Equal(t, ProtoBuf, Default("PUT", MIMEPROTOBUF))
+
+	assert.Equal(t, MsgPack, Default("POST", MIMEMSGPACK))
+	assert.Equal(t, MsgPack, Default("PUT", MIMEMSGPACK2))
+
+	assert.Equal(t, YAML, Default("POST", MIMEYAML))
+	assert.Equal(t, YAML, Default("PUT", MIMEYAML))
+
+	assert.Equal(t, TOML, Default("POST", MIMETOML))
+	assert.Equal(t, TOML, Default("PUT", MIMETOML))
 }
 
 func TestBindingJSONNilBody(t *testing.T) {
@@ -90,6 +108,132 @@ func TestBindingJSONStringMap(t *testing.T) {
 		`{"foo": "bar", "hello": "world"}`, `{"num": 2}`)
 }
 
+func TestBindingXML(t *testing.T) {
+	testBodyBinding(t,
+		XML, "xml",
+		"/", "/",
+		"<map><foo>bar</foo></map>", "<map><bar>foo</bar></map>")
+}
+
+func TestBindingXMLFail(t *testing.T) {
+	testBodyBindingFail(t,
+		XML, "xml",
+		"/", "/",
+		"<map><foo>bar<foo></map>", "<map><bar>foo</bar></map>")
+}
+
+func TestBindingYAML(t *testing.T) {
+	testBodyBinding(t,
+		YAML, "yaml",
+		"/", "/",
+		`foo: bar`, `bar: foo`)
+}
+
+func TestBindingYAMLStringMap(t *testing.T) {
+	// YAML is a superset of JSON, so the test below is JSON (to avoid newlines)
+	testBodyBindingStringMap(t, YAML,
+		"/", "/",
+		`{"foo": "bar", "hello": "world"}`, `{"nested": {"foo": "bar"}}`)
+}
+
+func TestBindingYAMLFail(t *testing.T) {
+	testBodyBindingFail(t,
+		YAML, "yaml",
+		"/", "/",
+		`foo:\nbar`, `bar: foo`)
+}
+
+func TestBindingTOML(t *testing.T) {
+	testBodyBinding(t,
+		TOML, "toml",
+		"/", "/",
+		`foo="bar"`, `bar="foo"`)
+}
+
+func TestBindingTOMLFail(t *testing.T) {
+	testBodyBindingFail(t,
+		TOML, "toml",
+		"/", "/",
+		`foo=\n"bar"`, `bar="foo"`)
+}
+
+func TestBindingMsgPack(t *testing.T) {
+	test := FooStruct{
+		Foo: "bar",
+	}
+
+	h := new(codec.MsgpackHandle)
+	assert.NotNil(t, h)
+	buf := bytes.NewBuffer([]byte{})
+	assert.NotNil(t, buf)
+	err := codec.NewEncoder(buf, h).Encode(test)
+	assert.NoError(t, err)
+
+	data := buf.Bytes()
+
+	testMsgPackBodyBinding(t,
+		MsgPack, "msgpack",
+		"/", "/",
+		string(data), string(data[1:]))
+}
+
+func testMsgPackBodyBinding(t *testing.T, b Binding, name, path, badPath, body, badBody string) {
+	assert.Equal(t, name, b.Name())
+
+	obj := FooStruct{}
+	req := requestWithBody("POST", path, body)
+	req.Header.Add("Content-Type", MIMEMSGPACK)
+	err := b.Bind(req, &obj)
+	assert.NoError(t, err)
+	assert.Equal(t, "bar", obj.Foo)
+
+	obj = FooStruct{}
+	req = requestWithBody("POST", badPath, badBody)
+	req.Header.Add("Content-Type", MIMEMSGPACK)
+	err = MsgPack.Bind(req, &obj)
+	assert.Error(t, err)
+}
+
+func TestBindingProtoBuf(t *testing.T) {
+	test := wrapperspb.String("yes")
+	data, _ := proto.Marshal(test)
+
+	obj := wrapperspb.StringValue{}
+	req := requestWithBody("POST", "/", string(data))
+	req.Header.Add("Content-Type", MIMEPROTOBUF)
+	assert.Equal(t, "protobuf", ProtoBuf.Name())
+	assert.NoError(t, ProtoBuf.Bind(req, &obj))
+	assert.Equal(t, "yes", obj.Value)
+
+	body := wrapperspb.StringValue{}
+	assert.NoError(t, ProtoBuf.BindBody(data, &body))
+	assert.Equal(t, "yes", body.Value)
+
+	assert.Error(t, ProtoBuf.BindBody(data, &FooStruct{}))
+	assert.Error(t, ProtoBuf.BindBody(data[1:], &obj))
+}
+
+func TestBindingBodyImplementations(t *testing.T) {
+	for _, b := range []BindingBody{JSON, XML, YAML, TOML, MsgPack, ProtoBuf} {
+		assert.Error(t, b.Bind(&http.Request{}, &FooStruct{}), b.Name())
+	}
+}
+
+func testBodyBindingFail(t *testing.T, b Binding, name, path, badPath, body, badBody string) {
+	assert.Equal(t, name, b.Name())
+
+	obj := FooStruct{}
+	req := requestWithBody("POST", path, body)
+	err := b.Bind(req, &obj)
+	assert.Error(t, err)
+	assert.Equal(t, "", obj.Foo)
+
+	obj = FooStruct{}
+	req = requestWithBody("POST", badPath, badBody)
+	err = JSON.Bind(req, &obj)
+	assert.Error(t, err)
+}
+
 func testBodyBinding(t *testing.T, b Binding, name, path, badPath, body, badBody string) {
 	assert.Equal(t, name, b.Name())
 
@@ -205,3 +349,71 @@ func testBodyBindingDisallowUnknownFields(t *testing.T, b Binding, path, badPath
 	assert.Error(t, err)
 	assert.Contains(t, err.Error(), "what")
 }
+
+type allSourcesStruct struct {
+	ID      int    `uri:"id" form:"id" json:"id" binding:"required"`
+	Name    string `json:"name" form:"name" binding:"required"`
+	Page    int    `form:"page,default=1" json:"page"`
+	Size    int    `form:"size,default=20" json:"size"`
+	Token   string `header:"X-Token" form:"-" binding:"required"`
+	Comment string `form:"comment"`
+}
+
+func TestBindingAll(t *testing.T) {
+	req := requestWithBody("POST", "/?page=3&id=7", `{"id": 5, "name": "rum", "size": 50}`)
+	req.Header.Set("Content-Type", MIMEJSON)
+	req.Header.Set("X-Token", "secret")
+
+	var obj allSourcesStruct
+	err := BindAll(req, map[string][]string{"id": {"42"}}, &obj)
+	assert.NoError(t, err)
+	assert.Equal(t, 42, obj.ID)
+	assert.Equal(t, "rum", obj.Name)
+	assert.Equal(t, 3, obj.Page)
+	assert.Equal(t, 50, obj.Size)
+	assert.Equal(t, "secret", obj.Token)
+}
+
+func TestBindingAllDefault(t *testing.T) {
+	req := requestWithBody("GET", "/?name=rum&size=0", "")
+	req.Header.Set("X-Token", "secret")
+
+	var obj allSourcesStruct
+	err := BindAll(req, map[string][]string{"id": {"1"}}, &obj)
+	assert.NoError(t, err)
+	assert.Equal(t, 1, obj.Page)
+	// an explicit zero from a source wins over the default
+	assert.Equal(t, 0, obj.Size)
+}
+
+func TestBindingAllForm(t *testing.T) {
+	req := requestWithBody("POST", "/?comment=query", "name=rum&comment=form")
+	req.Header.Set("Content-Type", MIMEPOSTForm)
+	req.Header.Set("X-Token", "secret")
+
+	var obj allSourcesStruct
+	err := BindAll(req, map[string][]string{"id": {"1"}}, &obj)
+	assert.NoError(t, err)
+	assert.Equal(t, "rum", obj.Name)
+	assert.Equal(t, "query", obj.Comment)
+}
+
+func TestBindingAllValidatesOnce(t *testing.T) {
+	// Every source alone fails the required rules, together they pass.
+	req := requestWithBody("POST", "/", `{"name": "rum"}`)
+	req.Header.Set("Content-Type", MIMEJSON)
+	req.Header.Set("X-Token", "secret")
+
+	var obj allSourcesStruct
+	assert.NoError(t, BindAll(req, map[string][]string{"id": {"9"}}, &obj))
+
+	req = requestWithBody("POST", "/", `{"name": "rum"}`)
+	req.Header.Set("Content-Type", MIMEJSON)
+	obj = allSourcesStruct{}
+	assert.Error(t, BindAll(req, map[string][]string{"id": {"9"}}, &obj))
+
+	req = requestWithBody("POST", "/", `{"name": `)
+	req.Header.Set("Content-Type", MIMEJSON)
+	assert.Error(t, BindAll(req, nil, &obj))
+	assert.Error(t, BindAll(req, nil, obj))
+}
@@ -0,0 +1,98 @@
+package binding
+
+import (
+	"encoding"
+	"fmt"
+	"reflect"
+	"sync"
+)
+
+// BindUnmarshaler is the interface implemented by types that can decode
+// themselves from a form, query, header or uri value.
+type BindUnmarshaler interface {
+	// UnmarshalParam decodes and assigns a value from a form or query param.
+	UnmarshalParam(param string) error
+}
+
+// DecodeFunc decodes a single form, query, header or uri value.
+type DecodeFunc func(string) (interface{}, error)
+
+var (
+	decodersMu sync.RWMutex
+	decoders   = make(map[reflect.Type]DecodeFunc)
+
+	bindUnmarshalerType = reflect.TypeOf((*BindUnmarshaler)(nil)).Elem()
+	textUnmarshalerType = reflect.TypeOf((*encoding.TextUnmarshaler)(nil)).Elem()
+)
+
+// RegisterDecoder registers fn to decode the values bound into fields of type
+// typ, e.g. UUIDs or decimals from a third party package. The value returned
+// by fn must be assignable or convertible to typ. A registered decoder takes
+// precedence over BindUnmarshaler, encoding.TextUnmarshaler and the built-in
+// conversions. It is meant to be called at init time.
+func RegisterDecoder(typ reflect.Type, fn DecodeFunc) {
+	decodersMu.Lock()
+	decoders[typ] = fn
+	decodersMu.Unlock()
+
+	// compiled plans hold the conversion functions, recompile them lazily
+	planCache.Range(func(key, _ interface{}) bool {
+		planCache.Delete(key)
+		return true
+	})
+}
+
+// customSetFunc returns the conversion function of t when t has a registered
+// decoder or implements BindUnmarshaler or encoding.TextUnmarshaler.
+// Empty values leave the field zeroed, as for the built-in numeric types.
+func customSetFunc(t reflect.Type) (setFunc, bool) {
+	decodersMu.RLock()
+	fn, ok := decoders[t]
+	decodersMu.RUnlock()
+	if ok {
+		return func(val string, value reflect.Value, _ reflect.StructField) error {
+			if val == "" {
+				value.Set(reflect.Zero(t))
+				return nil
+			}
+			v, err := fn(val)
+			if err != nil {
+				return err
+			}
+			rv := reflect.ValueOf(v)
+			switch {
+			case !rv.IsValid():
+				value.Set(reflect.Zero(t))
+			case rv.Type().AssignableTo(t):
+				value.Set(rv)
+			case rv.Type().ConvertibleTo(t):
+				value.Set(rv.Convert(t))
+			default:
+				return fmt.Errorf("decoder for %s returned %s", t, rv.Type())
+			}
+			return nil
+		}, true
+	}
+
+	ptr := reflect.PtrTo(t)
+	switch {
+	case ptr.Implements(bindUnmarshalerType):
+		return func(val string, value reflect.Value, _ reflect.StructField) error {
+			if val == "" {
+				value.Set(reflect.Zero(t))
+				return nil
+			}
+			return value.Addr().Interface().(BindUnmarshaler).UnmarshalParam(val)
+		}, true
+	case ptr.Implements(textUnmarshalerType) && t != timeType:
+		// time.Time keeps its own handling of the time_format tags
+		return func(val string, value reflect.Value, _ reflect.StructField) error {
+			if val == "" {
+				value.Set(reflect.Zero(t))
+				return nil
+			}
+			return value.Addr().Interface().(encoding.TextUnmarshaler).UnmarshalText([]byte(val))
+		}, true
+	}
+	return nil, false
+}
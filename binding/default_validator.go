@@ -5,6 +5,7 @@
 package binding
 
 import (
+	"context"
 	"fmt"
 	"reflect"
 	"strings"
@@ -18,35 +19,35 @@ type defaultValidator struct {
 	validate *validator.Validate
 }
 
+// SliceValidationError holds the validation error of every element of a
+// slice or array, at the index of the element. Valid elements are nil.
 type SliceValidationError []error
 
 // Error concatenates all error elements in SliceValidationError into a single string separated by \n.
 func (err SliceValidationError) Error() string {
-	n := len(err)
-	switch n {
-	case 0:
-		return ""
-	default:
-		var b strings.Builder
-		if err[0] != nil {
-			fmt.Fprintf(&b, "[%d]: %s", 0, err[0].Error())
+	var b strings.Builder
+	for i, e := range err {
+		if e == nil {
+			continue
 		}
-		if n > 1 {
-			for i := 1; i < n; i++ {
-				if err[i] != nil {
-					b.WriteString("\n")
-					fmt.Fprintf(&b, "[%d]: %s", i, err[i].Error())
-				}
-			}
+		if b.Len() > 0 {
+			b.WriteString("\n")
 		}
-		return b.String()
+		fmt.Fprintf(&b, "[%d]: %s", i, e.Error())
 	}
+	return b.String()
 }
 
-var _ StructValidator = &defaultValidator{}
+var _ ContextValidator = &defaultValidator{}
 
 // ValidateStruct receives any kind of type, but only performed struct or pointer to struct type.
 func (v *defaultValidator) ValidateStruct(obj interface{}) error {
+	return v.ValidateStructContext(context.Background(), obj)
+}
+
+// ValidateStructContext is like ValidateStruct, but passes ctx to the
+// validation functions registered with a context.
+func (v *defaultValidator) ValidateStructContext(ctx context.Context, obj interface{}) error {
 	if obj == nil {
 		return nil
 	}
@@ -54,18 +55,20 @@ func (v *defaultValidator) ValidateStruct(obj interface{}) error {
 	value := reflect.ValueOf(obj)
 	switch value.Kind() {
 	case reflect.Ptr:
-		return v.ValidateStruct(value.Elem().Interface())
+		return v.ValidateStructContext(ctx, value.Elem().Interface())
 	case reflect.Struct:
-		return v.validateStruct(obj)
+		return v.validateStruct(ctx, obj)
 	case reflect.Slice, reflect.Array:
 		count := value.Len()
-		validateRet := make(SliceValidationError, 0)
+		validateRet := make(SliceValidationError, count)
+		failed := false
 		for i := 0; i < count; i++ {
-			if err := v.ValidateStruct(value.Index(i).Interface()); err != nil {
-				validateRet = append(validateRet, err)
+			if err := v.ValidateStructContext(ctx, value.Index(i).Interface()); err != nil {
+				validateRet[i] = err
+				failed = true
 			}
 		}
-		if len(validateRet) == 0 {
+		if !failed {
 			return nil
 		}
 		return validateRet
@@ -75,9 +78,9 @@ func (v *defaultValidator) ValidateStruct(obj interface{}) error {
 }
 
 // validateStruct receives struct type
-func (v *defaultValidator) validateStruct(obj interface{}) error {
+func (v *defaultValidator) validateStruct(ctx context.Context, obj interface{}) error {
 	v.lazyinit()
-	return v.validate.Struct(obj)
+	return v.validate.StructCtx(ctx, obj)
 }
 
 // Engine returns the underlying validator engine which powers the default
@@ -93,5 +96,6 @@ func (v *defaultValidator) lazyinit() {
 	v.once.Do(func() {
 		v.validate = validator.New()
 		v.validate.SetTagName("binding")
+		registerFileValidations(v.validate)
 	})
 }
@@ -0,0 +1,383 @@
+package binding
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"reflect"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/go-playground/validator/v10"
+)
+
+// FieldError describes why a single field could not be bound, either because
+// its value could not be converted or because it failed the validation.
+type FieldError struct {
+	// Field is the path of the struct field, e.g. "Address.Zip" or "Items[1].Name".
+	Field string
+	// Key is the name of the field in its source, taken from the tag, e.g. "zip".
+	Key string
+	// Source is where the value comes from: "query", "form", "header", "uri",
+	// "json", ... It is empty when it can not be told apart, e.g. for a
+	// required field missing from every source of BindAll.
+	Source string
+	// Value is the raw value as received, or as seen by the validator.
+	Value string
+	// Reason is a short machine friendly reason, such as "invalid syntax" for
+	// conversion failures or the failing tag like "max=32" for validation ones.
+	Reason string
+	// Message is a human readable message, set by Translate.
+	Message string
+	// Err is the underlying error.
+	Err error
+}
+
+// Error implements the error interface.
+func (e *FieldError) Error() string {
+	var b strings.Builder
+	b.WriteString("field ")
+	b.WriteString(e.Field)
+	if e.Source != "" || e.Key != "" {
+		b.WriteString(" (")
+		b.WriteString(e.Source)
+		if e.Source != "" && e.Key != "" {
+			b.WriteByte(' ')
+		}
+		if e.Key != "" {
+			b.WriteString(strconv.Quote(e.Key))
+		}
+		b.WriteByte(')')
+	}
+	b.WriteString(": ")
+	b.WriteString(e.Reason)
+	return b.String()
+}
+
+// Unwrap returns the underlying error.
+func (e *FieldError) Unwrap() error {
+	return e.Err
+}
+
+// Errors is the list of FieldError returned by the bindings. Use errors.As to
+// retrieve it from the error returned by Bind.
+type Errors []*FieldError
+
+// Error concatenates all field errors into a single string separated by \n.
+func (errs Errors) Error() string {
+	msgs := make([]string, len(errs))
+	for i, e := range errs {
+		msgs[i] = e.Error()
+	}
+	return strings.Join(msgs, "\n")
+}
+
+// Unwrap returns the underlying error of a single field error, so that
+// errors.Is keeps working with e.g. strconv.ErrSyntax.
+func (errs Errors) Unwrap() error {
+	if len(errs) == 1 {
+		return errs[0]
+	}
+	return nil
+}
+
+// newFieldError wraps a conversion error of the value val found at key.
+func newFieldError(field reflect.StructField, key, val string, err error) Errors {
+	return Errors{{
+		Field:  field.Name,
+		Key:    key,
+		Value:  val,
+		Reason: conversionReason(err),
+		Err:    err,
+	}}
+}
+
+func conversionReason(err error) string {
+	var numErr *strconv.NumError
+	var timeErr *time.ParseError
+	switch {
+	case errors.As(err, &numErr):
+		return numErr.Err.Error()
+	case errors.As(err, &timeErr):
+		return "invalid time"
+	case errors.Is(err, errUnknownType):
+		return errUnknownType.Error()
+	}
+	return err.Error()
+}
+
+// fieldErrorKey sets the key of the field errors found in err.
+func fieldErrorKey(err error, key string) error {
+	if errs, ok := err.(Errors); ok {
+		for _, e := range errs {
+			e.Key = key
+		}
+	}
+	return err
+}
+
+// withSource sets the source of the field errors found in err.
+func withSource(err error, source string) error {
+	if errs, ok := err.(Errors); ok {
+		for _, e := range errs {
+			e.Source = source
+		}
+	}
+	return err
+}
+
+// prefixField prepends the name of the parent field to the field errors in err.
+func prefixField(err error, name string) error {
+	if errs, ok := err.(Errors); ok && name != "" {
+		for _, e := range errs {
+			e.Field = name + "." + e.Field
+		}
+	}
+	return err
+}
+
+// sourceTags are the struct tags holding the key of a field for each source.
+var sourceTags = map[string]string{
+	"query":   "form",
+	"form":    "form",
+	"header":  "header",
+	"uri":     "uri",
+	"json":    "json",
+	"xml":     "xml",
+	"yaml":    "yaml",
+	"toml":    "toml",
+	"msgpack": "msgpack",
+}
+
+// validate validates obj and reports the failures as Errors of the given source.
+// ctx is passed to the validation functions registered with a context.
+func validate(ctx context.Context, obj interface{}, source string) error {
+	if Validator == nil {
+		return nil
+	}
+	var err error
+	if v, ok := Validator.(ContextValidator); ok {
+		err = v.ValidateStructContext(ctx, obj)
+	} else {
+		err = Validator.ValidateStruct(obj)
+	}
+	if err == nil {
+		return nil
+	}
+	var errs Errors
+	if !collectValidationErrors(&errs, err, "") {
+		return err
+	}
+	root := reflect.ValueOf(obj)
+	for _, e := range errs {
+		e.Source = source
+		if sf, ok := lookupField(root, e.Field); ok {
+			e.Key = fieldKey(sf, source)
+		}
+	}
+	return errs
+}
+
+// collectValidationErrors appends the failures of err to errs. It reports
+// false if err is not an error of the default validator.
+func collectValidationErrors(errs *Errors, err error, prefix string) bool {
+	switch verr := err.(type) {
+	case validator.ValidationErrors:
+		for _, fe := range verr {
+			ns := fe.StructNamespace()
+			// drop the name of the root struct
+			if i := strings.IndexByte(ns, '.'); i >= 0 {
+				ns = ns[i+1:]
+			}
+			if prefix != "" {
+				ns = prefix + "." + ns
+			}
+			reason := fe.Tag()
+			if fe.Param() != "" {
+				reason += "=" + fe.Param()
+			}
+			*errs = append(*errs, &FieldError{
+				Field:  ns,
+				Key:    fe.Field(),
+				Value:  fmt.Sprint(fe.Value()),
+				Reason: reason,
+				Err:    fe,
+			})
+		}
+		return true
+	case SliceValidationError:
+		for i, e := range verr {
+			if e == nil {
+				continue
+			}
+			if !collectValidationErrors(errs, e, prefix+"["+strconv.Itoa(i)+"]") {
+				return false
+			}
+		}
+		return true
+	}
+	return false
+}
+
+// fieldKey returns the key of the field in the given source.
+// When the source is unknown, the json then the form name is used.
+func fieldKey(sf reflect.StructField, source string) string {
+	tags := []string{"json", "form"}
+	if tag, ok := sourceTags[source]; ok {
+		tags = []string{tag}
+	}
+	for _, tag := range tags {
+		key, _ := head(sf.Tag.Get(tag), ",")
+		if key != "" && key != "-" {
+			return key
+		}
+	}
+	return sf.Name
+}
+
+// lookupField follows a field path such as "Items[1].Name" from v.
+func lookupField(v reflect.Value, path string) (reflect.StructField, bool) {
+	var sf reflect.StructField
+	found := false
+	for path != "" {
+		var seg string
+		seg, path = head(path, ".")
+		name, index := seg, ""
+		if i := strings.IndexByte(seg, '['); i >= 0 {
+			name, index = seg[:i], seg[i:]
+		}
+		for v.Kind() == reflect.Ptr || v.Kind() == reflect.Interface {
+			if v.IsNil() {
+				return sf, false
+			}
+			v = v.Elem()
+		}
+		if name != "" {
+			if v.Kind() != reflect.Struct {
+				return sf, false
+			}
+			f, ok := v.Type().FieldByName(name)
+			if !ok {
+				return sf, false
+			}
+			sf, found = f, true
+			v = v.FieldByIndex(f.Index)
+		}
+		for index != "" {
+			end := strings.IndexByte(index, ']')
+			if end < 0 {
+				return sf, false
+			}
+			i, err := strconv.Atoi(index[1:end])
+			index = index[end+1:]
+			for v.Kind() == reflect.Ptr {
+				v = v.Elem()
+			}
+			if err != nil || (v.Kind() != reflect.Slice && v.Kind() != reflect.Array) || i >= v.Len() {
+				return sf, false
+			}
+			v = v.Index(i)
+		}
+	}
+	return sf, found
+}
+
+// lookupFieldAddr returns the address of the field at path, see lookupField.
+func lookupFieldAddr(v reflect.Value, path string) (uintptr, bool) {
+	for path != "" {
+		var name string
+		name, path = head(path, ".")
+		for v.Kind() == reflect.Ptr {
+			if v.IsNil() {
+				return 0, false
+			}
+			v = v.Elem()
+		}
+		if strings.IndexByte(name, '[') >= 0 || v.Kind() != reflect.Struct {
+			return 0, false
+		}
+		f, ok := v.Type().FieldByName(name)
+		if !ok {
+			return 0, false
+		}
+		v = v.FieldByIndex(f.Index)
+	}
+	if !v.CanAddr() {
+		return 0, false
+	}
+	return v.UnsafeAddr(), true
+}
+
+// jsonFieldError converts a type mismatch reported by encoding/json.
+func jsonFieldError(err error, obj interface{}) error {
+	if err == nil {
+		return nil
+	}
+	var typeErr *json.UnmarshalTypeError
+	if !errors.As(err, &typeErr) || typeErr.Field == "" {
+		return err
+	}
+	field := typeErr.Field
+	key := field
+	if i := strings.LastIndexByte(field, '.'); i >= 0 {
+		key = field[i+1:]
+	}
+	if path, ok := structPathByTag(reflect.TypeOf(obj), field, "json"); ok {
+		field = path
+	}
+	return Errors{{
+		Field:  field,
+		Key:    key,
+		Source: "json",
+		Value:  typeErr.Value,
+		Reason: "cannot unmarshal " + typeErr.Value + " into " + typeErr.Type.String(),
+		Err:    err,
+	}}
+}
+
+// structPathByTag translates a path of tag names, e.g. "address.zip", into
+// the path of the struct fields, e.g. "Address.Zip".
+func structPathByTag(t reflect.Type, path, tag string) (string, bool) {
+	var fields []string
+	for path != "" {
+		var key string
+		key, path = head(path, ".")
+		for t.Kind() == reflect.Ptr || t.Kind() == reflect.Slice || t.Kind() == reflect.Array || t.Kind() == reflect.Map {
+			t = t.Elem()
+		}
+		if t.Kind() != reflect.Struct {
+			return "", false
+		}
+		sf, ok := fieldByTag(t, key, tag)
+		if !ok {
+			return "", false
+		}
+		fields = append(fields, sf.Name)
+		t = sf.Type
+	}
+	return strings.Join(fields, "."), true
+}
+
+func fieldByTag(t reflect.Type, key, tag string) (reflect.StructField, bool) {
+	for i := 0; i < t.NumField(); i++ {
+		sf := t.Field(i)
+		name, _ := head(sf.Tag.Get(tag), ",")
+		if name == key || (name == "" && strings.EqualFold(sf.Name, key)) {
+			return sf, true
+		}
+		if sf.Anonymous && name == "" {
+			ft := sf.Type
+			if ft.Kind() == reflect.Ptr {
+				ft = ft.Elem()
+			}
+			if ft.Kind() == reflect.Struct {
+				if f, ok := fieldByTag(ft, key, tag); ok {
+					return f, true
+				}
+			}
+		}
+	}
+	return reflect.StructField{}, false
+}
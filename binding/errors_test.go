@@ -0,0 +1,139 @@
+package binding
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+type errorsAddress struct {
+	Zip int `form:"zip" json:"zip" binding:"min=1000"`
+}
+
+type errorsStruct struct {
+	Name    string        `form:"name" header:"X-Name" json:"name" binding:"required,max=4"`
+	Age     int           `form:"age" header:"X-Age" uri:"age" json:"age"`
+	Tags    []int         `form:"tags"`
+	Address errorsAddress `json:"address"`
+}
+
+func fieldErrors(t *testing.T, err error) Errors {
+	var errs Errors
+	if !assert.True(t, errors.As(err, &errs), "%v is not binding.Errors", err) {
+		t.FailNow()
+	}
+	return errs
+}
+
+func TestBindingErrorsConversion(t *testing.T) {
+	req, _ := http.NewRequest("GET", "/?name=rum&age=ten", nil)
+	var obj errorsStruct
+	errs := fieldErrors(t, Query.Bind(req, &obj))
+
+	assert.Len(t, errs, 1)
+	assert.Equal(t, "Age", errs[0].Field)
+	assert.Equal(t, "age", errs[0].Key)
+	assert.Equal(t, "query", errs[0].Source)
+	assert.Equal(t, "ten", errs[0].Value)
+	assert.Equal(t, "invalid syntax", errs[0].Reason)
+	assert.True(t, errors.Is(errs, strconv.ErrSyntax))
+	assert.Equal(t, `field Age (query "age"): invalid syntax`, errs.Error())
+
+	req, _ = http.NewRequest("GET", "/?name=rum&tags=1&tags=x", nil)
+	errs = fieldErrors(t, Form.Bind(req, &obj))
+	assert.Equal(t, "Tags[1]", errs[0].Field)
+	assert.Equal(t, "tags", errs[0].Key)
+	assert.Equal(t, "form", errs[0].Source)
+	assert.Equal(t, "x", errs[0].Value)
+
+	req, _ = http.NewRequest("GET", "/", nil)
+	req.Header.Set("X-Name", "rum")
+	req.Header.Set("X-Age", "99999999999999999999")
+	errs = fieldErrors(t, Header.Bind(req, &obj))
+	assert.Equal(t, "header", errs[0].Source)
+	assert.Equal(t, "X-Age", errs[0].Key)
+	assert.Equal(t, "value out of range", errs[0].Reason)
+
+	errs = fieldErrors(t, Uri.BindUri(map[string][]string{"age": {"-"}}, &obj))
+	assert.Equal(t, "uri", errs[0].Source)
+}
+
+func TestBindingErrorsNested(t *testing.T) {
+	var obj struct {
+		Inner struct {
+			Deep struct {
+				N int `form:"n"`
+			}
+		}
+	}
+	errs := fieldErrors(t, mapForm(&obj, map[string][]string{"n": {"x"}}))
+	assert.Equal(t, "Inner.Deep.N", errs[0].Field)
+	assert.Equal(t, "n", errs[0].Key)
+}
+
+func TestBindingErrorsValidation(t *testing.T) {
+	req, _ := http.NewRequest("GET", "/?name=rumrum", nil)
+	var obj errorsStruct
+	errs := fieldErrors(t, Query.Bind(req, &obj))
+
+	assert.Len(t, errs, 2)
+	assert.Equal(t, "Name", errs[0].Field)
+	assert.Equal(t, "name", errs[0].Key)
+	assert.Equal(t, "query", errs[0].Source)
+	assert.Equal(t, "rumrum", errs[0].Value)
+	assert.Equal(t, "max=4", errs[0].Reason)
+	assert.Equal(t, "Address.Zip", errs[1].Field)
+	assert.Equal(t, "min=1000", errs[1].Reason)
+
+	req = requestWithBody("POST", "/", `{"name": "rum", "address": {"zip": 12}}`)
+	errs = fieldErrors(t, JSON.Bind(req, &obj))
+	assert.Len(t, errs, 1)
+	assert.Equal(t, "Address.Zip", errs[0].Field)
+	assert.Equal(t, "zip", errs[0].Key)
+	assert.Equal(t, "json", errs[0].Source)
+	assert.Equal(t, "12", errs[0].Value)
+}
+
+func TestBindingErrorsJSONType(t *testing.T) {
+	var obj errorsStruct
+	req := requestWithBody("POST", "/", `{"name": "rum", "address": {"zip": "x"}}`)
+	errs := fieldErrors(t, JSON.Bind(req, &obj))
+
+	assert.Equal(t, "Address.Zip", errs[0].Field)
+	assert.Equal(t, "zip", errs[0].Key)
+	assert.Equal(t, "json", errs[0].Source)
+	assert.Equal(t, "string", errs[0].Value)
+	assert.Equal(t, "cannot unmarshal string into int", errs[0].Reason)
+}
+
+func TestBindingErrorsSlice(t *testing.T) {
+	var objs []errorsStruct
+	req := requestWithBody("POST", "/", `[{"name": "rum", "address": {"zip": 1000}}, {"address": {"zip": 1000}}]`)
+	errs := fieldErrors(t, JSON.Bind(req, &objs))
+
+	assert.Len(t, errs, 1)
+	assert.Equal(t, "[1].Name", errs[0].Field)
+	assert.Equal(t, "required", errs[0].Reason)
+}
+
+func TestBindingErrorsAll(t *testing.T) {
+	req := requestWithBody("POST", "/?name=toolong", `{"age": 1, "address": {"zip": 10}}`)
+	req.Header.Set("Content-Type", MIMEJSON)
+	var obj errorsStruct
+	errs := fieldErrors(t, BindAll(req, nil, &obj))
+
+	assert.Len(t, errs, 2)
+	assert.Equal(t, "Name", errs[0].Field)
+	assert.Equal(t, "query", errs[0].Source)
+	assert.Equal(t, "name", errs[0].Key)
+	assert.Equal(t, "Address.Zip", errs[1].Field)
+	assert.Equal(t, "json", errs[1].Source)
+	assert.Equal(t, "zip", errs[1].Key)
+
+	req = requestWithBody("GET", "/?age=x", "")
+	errs = fieldErrors(t, BindAll(req, nil, &obj))
+	assert.Equal(t, "query", errs[0].Source)
+}
@@ -0,0 +1,130 @@
+package binding
+
+import (
+	"io"
+	"mime/multipart"
+	"net/http"
+	"reflect"
+	"strconv"
+	"strings"
+
+	"github.com/go-playground/validator/v10"
+)
+
+// uploadedFile is the value the validator sees for a multipart.FileHeader.
+// The validator would walk a struct instead of applying the tags of the
+// field, so the header is wrapped in a non-struct type.
+type uploadedFile []*multipart.FileHeader
+
+// String returns the name of the file, reported as the value of the field
+// when a validation fails.
+func (f uploadedFile) String() string {
+	if len(f) != 1 || f[0] == nil {
+		return ""
+	}
+	return f[0].Filename
+}
+
+func fileHeaderValue(field reflect.Value) interface{} {
+	if field.CanAddr() {
+		return uploadedFile{field.Addr().Interface().(*multipart.FileHeader)}
+	}
+	fh := field.Interface().(multipart.FileHeader)
+	return uploadedFile{&fh}
+}
+
+// registerFileValidations registers the validations of uploaded files:
+//
+//	max_size=10MB               the file is at most 10 MB (B, KB, MB and GB
+//	                            are powers of 1024), a malformed size fails
+//	                            the field
+//	mime=image/png;image/jpeg   the type detected from the content of the
+//	                            file is one of the listed types, "image/*"
+//	                            accepts every image
+//
+// The types of mime are separated with ';' or spaces, like oneof. '|' is the
+// "or" operator of the validator, which requires a whole validation on each
+// side: `mime=image/png|mime=image/jpeg`. A type http.DetectContentType never
+// returns, such as "application/json", fails the field.
+func registerFileValidations(v *validator.Validate) {
+	v.RegisterCustomTypeFunc(fileHeaderValue, multipart.FileHeader{})
+	v.RegisterValidation("max_size", isMaxSize)
+	v.RegisterValidation("mime", isMIME)
+}
+
+func uploadedFileOf(fl validator.FieldLevel) (*multipart.FileHeader, bool) {
+	files, ok := fl.Field().Interface().(uploadedFile)
+	if !ok || len(files) != 1 {
+		return nil, false
+	}
+	return files[0], true
+}
+
+func isMaxSize(fl validator.FieldLevel) bool {
+	fh, ok := uploadedFileOf(fl)
+	if !ok {
+		return false
+	}
+	max, err := parseSize(fl.Param())
+	if err != nil {
+		return false
+	}
+	return fh.Size <= max
+}
+
+// isMIME reports whether the type detected from the content of the file is
+// one of the types of the parameter.
+func isMIME(fl validator.FieldLevel) bool {
+	fh, ok := uploadedFileOf(fl)
+	if !ok {
+		return false
+	}
+	f, err := fh.Open()
+	if err != nil {
+		return false
+	}
+	defer f.Close()
+	var head [512]byte
+	n, err := io.ReadFull(f, head[:])
+	if err != nil && err != io.ErrUnexpectedEOF && err != io.EOF {
+		return false
+	}
+	contentType := filterFlags(http.DetectContentType(head[:n]))
+
+	types := strings.FieldsFunc(fl.Param(), func(r rune) bool {
+		return r == ';' || r == ' '
+	})
+	for _, t := range types {
+		if t == contentType || strings.HasSuffix(t, "/*") && strings.HasPrefix(contentType, t[:len(t)-1]) {
+			return true
+		}
+	}
+	return false
+}
+
+var sizeUnits = []struct {
+	suffix string
+	size   int64
+}{
+	{"GB", 1 << 30},
+	{"MB", 1 << 20},
+	{"KB", 1 << 10},
+	{"B", 1},
+}
+
+// parseSize parses a size such as "512", "100KB" or "10MB".
+func parseSize(s string) (int64, error) {
+	s = strings.ToUpper(strings.TrimSpace(s))
+	unit := int64(1)
+	for _, u := range sizeUnits {
+		if strings.HasSuffix(s, u.suffix) {
+			s, unit = strings.TrimSpace(s[:len(s)-len(u.suffix)]), u.size
+			break
+		}
+	}
+	n, err := strconv.ParseFloat(s, 64)
+	if err != nil {
+		return 0, err
+	}
+	return int64(n * float64(unit)), nil
+}
@@ -0,0 +1,122 @@
+package binding
+
+import (
+	"bytes"
+	"mime/multipart"
+	"net/http"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+var pngContent = append([]byte("\x89PNG\x0D\x0A\x1A\x0A"), make([]byte, 100)...)
+
+func fileRequest(t *testing.T, name string, content []byte) *http.Request {
+	buf := new(bytes.Buffer)
+	mw := multipart.NewWriter(buf)
+	if content != nil {
+		w, err := mw.CreateFormFile("file", name)
+		assert.NoError(t, err)
+		w.Write(content)
+	}
+	assert.NoError(t, mw.Close())
+	req, _ := http.NewRequest(http.MethodPost, "/", buf)
+	req.Header.Set("Content-Type", mw.FormDataContentType())
+	return req
+}
+
+func TestFileValidations(t *testing.T) {
+	type upload struct {
+		File *multipart.FileHeader `form:"file" binding:"required,max_size=1KB,mime=image/png image/jpeg"`
+	}
+
+	var obj upload
+	assert.NoError(t, FormMultipart.Bind(fileRequest(t, "a.png", pngContent), &obj))
+	assert.Equal(t, "a.png", obj.File.Filename)
+
+	err := FormMultipart.Bind(fileRequest(t, "a.png", append(pngContent, make([]byte, 1024)...)), &obj)
+	if assert.IsType(t, Errors{}, err) {
+		e := err.(Errors)[0]
+		assert.Equal(t, "File", e.Field)
+		assert.Equal(t, "file", e.Key)
+		assert.Equal(t, "a.png", e.Value)
+		assert.Equal(t, "max_size=1KB", e.Reason)
+	}
+
+	err = FormMultipart.Bind(fileRequest(t, "a.png", []byte("not an image")), &obj)
+	if assert.IsType(t, Errors{}, err) {
+		assert.Equal(t, "mime=image/png image/jpeg", err.(Errors)[0].Reason)
+	}
+
+	obj = upload{}
+	err = FormMultipart.Bind(fileRequest(t, "", nil), &obj)
+	if assert.IsType(t, Errors{}, err) {
+		assert.Equal(t, "required", err.(Errors)[0].Reason)
+	}
+}
+
+func TestFileValidationsOr(t *testing.T) {
+	var obj struct {
+		Files []*multipart.FileHeader `form:"file" binding:"dive,mime=text/*|mime=image/png"`
+	}
+	assert.NoError(t, FormMultipart.Bind(fileRequest(t, "a.png", pngContent), &obj))
+	assert.NoError(t, FormMultipart.Bind(fileRequest(t, "a.txt", []byte("text")), &obj))
+	assert.Error(t, FormMultipart.Bind(fileRequest(t, "a.gif", []byte("GIF89a")), &obj))
+}
+
+func TestFileValidationsSemicolon(t *testing.T) {
+	var obj struct {
+		Avatar *multipart.FileHeader `form:"file" binding:"max_size=10MB,mime=image/png;image/jpeg"`
+	}
+	assert.NoError(t, FormMultipart.Bind(fileRequest(t, "a.png", pngContent), &obj))
+	assert.NoError(t, FormMultipart.Bind(fileRequest(t, "a.jpg", []byte("\xFF\xD8\xFF\xE0")), &obj))
+	err := FormMultipart.Bind(fileRequest(t, "a.gif", []byte("GIF89a")), &obj)
+	if assert.IsType(t, Errors{}, err) {
+		assert.Equal(t, "Avatar", err.(Errors)[0].Field)
+		assert.Equal(t, "mime=image/png;image/jpeg", err.(Errors)[0].Reason)
+	}
+}
+
+func TestFileValidationsUnknownType(t *testing.T) {
+	var obj struct {
+		File *multipart.FileHeader `form:"file" binding:"mime=image/png;application/json"`
+	}
+	assert.NoError(t, FormMultipart.Bind(fileRequest(t, "a.png", pngContent), &obj))
+	var err error
+	assert.NotPanics(t, func() {
+		err = FormMultipart.Bind(fileRequest(t, "a.json", []byte(`{"a": 1}`)), &obj)
+	})
+	if assert.IsType(t, Errors{}, err) {
+		assert.Equal(t, "mime=image/png;application/json", err.(Errors)[0].Reason)
+	}
+}
+
+func TestFileValidationsInvalidMaxSize(t *testing.T) {
+	var obj struct {
+		File *multipart.FileHeader `form:"file" binding:"max_size=ten"`
+	}
+	var err error
+	assert.NotPanics(t, func() {
+		err = FormMultipart.Bind(fileRequest(t, "a.png", pngContent), &obj)
+	})
+	if assert.IsType(t, Errors{}, err) {
+		assert.Equal(t, "max_size=ten", err.(Errors)[0].Reason)
+	}
+}
+
+func TestParseSize(t *testing.T) {
+	for s, want := range map[string]int64{
+		"512":    512,
+		"100B":   100,
+		"1KB":    1 << 10,
+		"10MB":   10 << 20,
+		"1.5 kb": 1536,
+		"2GB":    2 << 30,
+	} {
+		got, err := parseSize(s)
+		assert.NoError(t, err, s)
+		assert.Equal(t, want, got, s)
+	}
+	_, err := parseSize("ten MB")
+	assert.Error(t, err)
+}
@@ -9,6 +9,8 @@ import (
 	"net/http"
 )
 
+// defaultMemory is the memory of the multipart forms parsed by the bindings.
+// Context parses them beforehand with Engine.MaxMultipartMemory.
 const defaultMemory = 32 << 20
 
 type formBinding struct{}
@@ -29,7 +31,7 @@ func (formBinding) Bind(req *http.Request, obj interface{}) error {
 	if err := mapForm(obj, req.Form); err != nil {
 		return err
 	}
-	return validate(obj)
+	return validate(req.Context(), obj, "form")
 }
 
 func (formPostBinding) Name() string {
@@ -43,7 +45,7 @@ func (formPostBinding) Bind(req *http.Request, obj interface{}) error {
 	if err := mapForm(obj, req.PostForm); err != nil {
 		return err
 	}
-	return validate(obj)
+	return validate(req.Context(), obj, "form")
 }
 
 func (formMultipartBinding) Name() string {
@@ -58,5 +60,5 @@ func (formMultipartBinding) Bind(req *http.Request, obj interface{}) error {
 		return err
 	}
 
-	return validate(obj)
+	return validate(req.Context(), obj, "form")
 }
@@ -6,6 +6,7 @@ package binding
 
 import (
 	"bytes"
+	"context"
 	"encoding/json"
 	"errors"
 	"io"
@@ -33,11 +34,17 @@ func (jsonBinding) Bind(req *http.Request, obj interface{}) error {
 	if req == nil || req.Body == nil {
 		return errors.New("invalid request")
 	}
-	return decodeJSON(req.Body, obj)
+	if err := decodeJSON(req.Body, obj); err != nil {
+		return err
+	}
+	return validate(req.Context(), obj, "json")
 }
 
 func (jsonBinding) BindBody(body []byte, obj interface{}) error {
-	return decodeJSON(bytes.NewReader(body), obj)
+	if err := decodeJSON(bytes.NewReader(body), obj); err != nil {
+		return err
+	}
+	return validate(context.Background(), obj, "json")
 }
 
 func decodeJSON(r io.Reader, obj interface{}) error {
@@ -48,8 +55,5 @@ func decodeJSON(r io.Reader, obj interface{}) error {
 	if EnableDecoderDisallowUnknownFields {
 		decoder.DisallowUnknownFields()
 	}
-	if err := decoder.Decode(obj); err != nil {
-		return err
-	}
-	return validate(obj)
+	return jsonFieldError(decoder.Decode(obj), obj)
 }
@@ -11,6 +11,7 @@ import (
 	"reflect"
 	"strconv"
 	"strings"
+	"sync"
 	"time"
 
 	"github.com/MichaelDeSteven/rum/internal/bytesconv"
@@ -34,6 +35,10 @@ func mapForm(ptr interface{}, form map[string][]string) error {
 	return mapFormByTag(ptr, form, "form")
 }
 
+func mapQuery(ptr interface{}, query map[string][]string) error {
+	return withSource(mapFormByTag(ptr, query, "form"), "query")
+}
+
 func MapFormWithTag(ptr interface{}, form map[string][]string, tag string) error {
 	return mapFormByTag(ptr, form, tag)
 }
@@ -74,25 +79,150 @@ func (form formSource) TrySet(value reflect.Value, field reflect.StructField, ta
 }
 
 func mappingByPtr(ptr interface{}, setter setter, tag string) error {
-	_, err := mapping(reflect.ValueOf(ptr), emptyField, setter, tag)
-	return err
+	_, err := mapping(reflect.ValueOf(ptr), setter, tag)
+	return withSource(err, tag)
 }
 
-func mapping(value reflect.Value, field reflect.StructField, setter setter, tag string) (bool, error) {
-	if field.Tag.Get(tag) == "-" { // just ignoring this field
-		return false, nil
+func mapping(value reflect.Value, setter setter, tag string) (bool, error) {
+	plan := rootPlan(value.Type(), tag)
+	return plan.apply(value, setter)
+}
+
+type setOptions struct {
+	isDefaultExists bool
+	defaultValue    string
+	// set converts a single value into the field, or into an element of the
+	// field for slices and arrays.
+	set setFunc
+	// custom reports that set decodes the whole field, even if the field is
+	// a slice such as net.IP.
+	custom bool
+	// collectionFormat tells how the values of a slice or an array are
+	// serialized: "multi" (repeated keys, the default), "csv", "ssv", "tsv"
+	// or "pipes".
+	collectionFormat string
+}
+
+// fieldPlan is the compiled form of a struct field for one tag: the tag is
+// parsed once and the conversion function is resolved from the field type.
+type fieldPlan struct {
+	index int
+	field reflect.StructField
+	// key is the form key of the field, empty when the field itself is never
+	// set directly (anonymous structs and the root value).
+	key string
+	opt setOptions
+	// sub is the plan of the struct the field points to, if any.
+	sub *structPlan
+}
+
+type structPlan struct {
+	fields []fieldPlan
+}
+
+type planKey struct {
+	typ reflect.Type
+	tag string
+}
+
+// planCache caches the compiled *fieldPlan of a root type per tag.
+var planCache sync.Map
+
+func rootPlan(t reflect.Type, tag string) *fieldPlan {
+	key := planKey{t, tag}
+	if plan, ok := planCache.Load(key); ok {
+		return plan.(*fieldPlan)
+	}
+	plan := newFieldPlan(-1, emptyField, t, tag, make(map[reflect.Type]*structPlan))
+	actual, _ := planCache.LoadOrStore(key, plan)
+	return actual.(*fieldPlan)
+}
+
+// newFieldPlan compiles the plan of a field of type t. The building map holds
+// the struct plans being compiled, so that recursive types terminate.
+func newFieldPlan(index int, field reflect.StructField, t reflect.Type, tag string, building map[reflect.Type]*structPlan) *fieldPlan {
+	plan := &fieldPlan{index: index, field: field}
+
+	base := t
+	for base.Kind() == reflect.Ptr {
+		base = base.Elem()
+	}
+
+	if base.Kind() != reflect.Struct || !field.Anonymous {
+		plan.key, plan.opt = parseTag(field, tag)
+		plan.opt.set, plan.opt.custom = customSetFunc(base)
+		if !plan.opt.custom {
+			elem := base
+			if k := base.Kind(); k == reflect.Slice || k == reflect.Array {
+				elem = base.Elem()
+			}
+			plan.opt.set = setFuncOf(elem)
+		}
+	}
+
+	if base.Kind() == reflect.Struct {
+		plan.sub = newStructPlan(base, tag, building)
+	}
+	return plan
+}
+
+func newStructPlan(t reflect.Type, tag string, building map[reflect.Type]*structPlan) *structPlan {
+	if sp, ok := building[t]; ok {
+		return sp
+	}
+	sp := &structPlan{}
+	building[t] = sp
+
+	for i := 0; i < t.NumField(); i++ {
+		sf := t.Field(i)
+		if sf.PkgPath != "" && !sf.Anonymous { // unexported
+			continue
+		}
+		if sf.Tag.Get(tag) == "-" { // just ignoring this field
+			continue
+		}
+		sp.fields = append(sp.fields, *newFieldPlan(i, sf, sf.Type, tag, building))
+	}
+	return sp
+}
+
+func parseTag(field reflect.StructField, tag string) (string, setOptions) {
+	var setOpt setOptions
+
+	tagValue := field.Tag.Get(tag)
+	tagValue, opts := head(tagValue, ",")
+
+	if tagValue == "" { // default value is FieldName
+		tagValue = field.Name
+	}
+	if tagValue == "" { // when field is "emptyField" variable
+		return "", setOpt
 	}
 
-	vKind := value.Kind()
+	var opt string
+	for len(opts) > 0 {
+		opt, opts = head(opts, ",")
+
+		switch k, v := head(opt, "="); k {
+		case "default":
+			setOpt.isDefaultExists = true
+			setOpt.defaultValue = v
+		case "collection_format":
+			setOpt.collectionFormat = v
+		}
+	}
+	return tagValue, setOpt
+}
 
-	if vKind == reflect.Ptr {
+func (p *fieldPlan) apply(value reflect.Value, setter setter) (bool, error) {
+	if value.Kind() == reflect.Ptr {
 		var isNew bool
 		vPtr := value
 		if value.IsNil() {
 			isNew = true
 			vPtr = reflect.New(value.Type().Elem())
 		}
-		isSet, err := mapping(vPtr.Elem(), field, setter, tag)
+		isSet, err := p.apply(vPtr.Elem(), setter)
 		if err != nil {
 			return false, err
 		}
@@ -102,9 +232,12 @@ func mapping(value reflect.Value, field reflect.StructField, setter setter, tag
 		return isSet, nil
 	}
 
-	if vKind != reflect.Struct || !field.Anonymous {
-		ok, err := tryToSetValue(value, field, setter, tag)
+	if p.key != "" {
+		ok, err := setter.TrySet(value, p.field, p.key, p.opt)
 		if err != nil {
+			if _, isFieldErr := err.(Errors); !isFieldErr {
+				err = newFieldError(p.field, p.key, "", err)
+			}
 			return false, err
 		}
 		if ok {
@@ -112,137 +245,277 @@ func mapping(value reflect.Value, field reflect.StructField, setter setter, tag
 		}
 	}
 
-	if vKind == reflect.Struct {
-		tValue := value.Type()
-
-		var isSet bool
-		for i := 0; i < value.NumField(); i++ {
-			sf := tValue.Field(i)
-			if sf.PkgPath != "" && !sf.Anonymous { // unexported
-				continue
-			}
-			ok, err := mapping(value.Field(i), sf, setter, tag)
-			if err != nil {
-				return false, err
-			}
-			isSet = isSet || ok
+	if p.sub != nil {
+		isSet, err := p.sub.apply(value, setter)
+		if err != nil && !p.field.Anonymous {
+			err = prefixField(err, p.field.Name)
 		}
-		return isSet, nil
+		return isSet, err
 	}
 	return false, nil
 }
 
-type setOptions struct {
-	isDefaultExists bool
-	defaultValue    string
+func (sp *structPlan) apply(value reflect.Value, setter setter) (bool, error) {
+	var isSet bool
+	for i := range sp.fields {
+		f := &sp.fields[i]
+		ok, err := f.apply(value.Field(f.index), setter)
+		if err != nil {
+			return false, err
+		}
+		isSet = isSet || ok
+	}
+	return isSet, nil
 }
 
-func tryToSetValue(value reflect.Value, field reflect.StructField, setter setter, tag string) (bool, error) {
-	var tagValue string
-	var setOpt setOptions
-
-	tagValue = field.Tag.Get(tag)
-	tagValue, opts := head(tagValue, ",")
+func setByForm(value reflect.Value, field reflect.StructField, form map[string][]string, tagValue string, opt setOptions) (isSet bool, err error) {
+	set := opt.set
+	if set == nil {
+		set, opt.custom = customSetFunc(value.Type())
+		if !opt.custom {
+			set = setWithProperType
+		}
+	}
 
-	if tagValue == "" { // default value is FieldName
-		tagValue = field.Name
+	vs, ok := form[tagValue]
+	if k := value.Kind(); !ok && !opt.custom && (k == reflect.Struct || k == reflect.Map) {
+		if sub := deepObject(form, tagValue); sub != nil {
+			return setDeepObject(value, field, sub, tagValue)
+		}
 	}
-	if tagValue == "" { // when field is "emptyField" variable
+	if !ok && !opt.isDefaultExists {
 		return false, nil
 	}
 
-	var opt string
-	for len(opts) > 0 {
-		opt, opts = head(opts, ",")
-
-		if k, v := head(opt, "="); k == "default" {
-			setOpt.isDefaultExists = true
-			setOpt.defaultValue = v
+	if !opt.custom {
+		switch value.Kind() {
+		case reflect.Slice:
+			if !ok {
+				vs = []string{opt.defaultValue}
+			}
+			if vs, err = splitCollection(vs, opt.collectionFormat); err != nil {
+				return false, newFieldError(field, tagValue, strings.Join(vs, ","), err)
+			}
+			return true, fieldErrorKey(setSlice(vs, value, field, set), tagValue)
+		case reflect.Array:
+			if !ok {
+				vs = []string{opt.defaultValue}
+			}
+			if vs, err = splitCollection(vs, opt.collectionFormat); err != nil {
+				return false, newFieldError(field, tagValue, strings.Join(vs, ","), err)
+			}
+			if len(vs) != value.Len() {
+				err := fmt.Errorf("%q is not valid value for %s", vs, value.Type().String())
+				return false, newFieldError(field, tagValue, strings.Join(vs, ","), err)
+			}
+			return true, fieldErrorKey(setArray(vs, value, field, set), tagValue)
 		}
 	}
 
-	return setter.TrySet(value, field, tagValue, setOpt)
+	var val string
+	if !ok {
+		val = opt.defaultValue
+	}
+
+	if len(vs) > 0 {
+		val = vs[0]
+	}
+	if err := set(val, value, field); err != nil {
+		return true, newFieldError(field, tagValue, val, err)
+	}
+	return true, nil
 }
 
-func setByForm(value reflect.Value, field reflect.StructField, form map[string][]string, tagValue string, opt setOptions) (isSet bool, err error) {
-	vs, ok := form[tagValue]
-	if !ok && !opt.isDefaultExists {
-		return false, nil
+var collectionSeparators = map[string]string{
+	"csv":   ",",
+	"ssv":   " ",
+	"tsv":   "\t",
+	"pipes": "|",
+}
+
+// splitCollection splits every value of vs according to the collection format.
+func splitCollection(vs []string, format string) ([]string, error) {
+	if format == "" || format == "multi" {
+		return vs, nil
+	}
+	sep, ok := collectionSeparators[format]
+	if !ok {
+		return vs, fmt.Errorf("%q is not supported in the collection_format. (multi, csv, ssv, tsv, pipes)", format)
+	}
+	var out []string
+	for _, v := range vs {
+		if v == "" {
+			continue
+		}
+		out = append(out, strings.Split(v, sep)...)
 	}
+	return out, nil
+}
 
-	switch value.Kind() {
-	case reflect.Slice:
-		if !ok {
-			vs = []string{opt.defaultValue}
+// deepObject collects the values of the keys "key[sub]" of form into a new
+// form keyed by "sub", as sent by the OpenAPI deepObject style, e.g.
+// filter[name]=x&filter[address][city]=y gives name=x and address[city]=y.
+// It returns nil if there is no such key.
+func deepObject(form map[string][]string, key string) map[string][]string {
+	var sub map[string][]string
+	prefix := key + "["
+	for k, v := range form {
+		if !strings.HasPrefix(k, prefix) {
+			continue
 		}
-		return true, setSlice(vs, value, field)
-	case reflect.Array:
-		if !ok {
-			vs = []string{opt.defaultValue}
+		rest := k[len(prefix):]
+		end := strings.IndexByte(rest, ']')
+		if end <= 0 {
+			continue
 		}
-		if len(vs) != value.Len() {
-			return false, fmt.Errorf("%q is not valid value for %s", vs, value.Type().String())
+		if sub == nil {
+			sub = make(map[string][]string)
 		}
-		return true, setArray(vs, value, field)
+		sub[rest[:end]+rest[end+1:]] = v
+	}
+	return sub
+}
+
+// setDeepObject binds a deepObject form into a struct or a map with string keys.
+func setDeepObject(value reflect.Value, field reflect.StructField, form map[string][]string, key string) (bool, error) {
+	var isSet bool
+	var err error
+	switch {
+	case value.Kind() == reflect.Struct && value.Type() != timeType:
+		isSet, err = mapping(value.Addr(), formSource(form), "form")
+	case value.Kind() == reflect.Map && value.Type().Key().Kind() == reflect.String:
+		isSet, err = setDeepMap(value, field, form)
 	default:
-		var val string
-		if !ok {
-			val = opt.defaultValue
+		return false, nil
+	}
+	if errs, ok := err.(Errors); ok {
+		for _, e := range errs {
+			if strings.HasPrefix(e.Field, "[") {
+				e.Field = field.Name + e.Field
+			} else {
+				e.Field = field.Name + "." + e.Field
+			}
+			name, rest := e.Key, ""
+			if i := strings.IndexByte(name, '['); i >= 0 {
+				name, rest = name[:i], name[i:]
+			}
+			e.Key = key + "[" + name + "]" + rest
 		}
+	}
+	return isSet, err
+}
 
-		if len(vs) > 0 {
-			val = vs[0]
+func setDeepMap(value reflect.Value, field reflect.StructField, form map[string][]string) (bool, error) {
+	t := value.Type()
+	if value.IsNil() {
+		value.Set(reflect.MakeMap(t))
+	}
+	for k := range form {
+		name, _ := head(k, "[")
+		if value.MapIndex(reflect.ValueOf(name).Convert(t.Key())).IsValid() {
+			continue
 		}
-		return true, setWithProperType(val, value, field)
+		elem := reflect.New(t.Elem()).Elem()
+		if _, err := setByForm(elem, field, form, name, setOptions{}); err != nil {
+			if errs, ok := err.(Errors); ok {
+				errs[0].Field = "[" + name + "]"
+				errs[0].Key = name
+			}
+			return false, err
+		}
+		value.SetMapIndex(reflect.ValueOf(name).Convert(t.Key()), elem)
 	}
+	return true, nil
 }
 
-func setWithProperType(val string, value reflect.Value, field reflect.StructField) error {
-	switch value.Kind() {
-	case reflect.Int:
-		return setIntField(val, 0, value)
-	case reflect.Int8:
-		return setIntField(val, 8, value)
-	case reflect.Int16:
-		return setIntField(val, 16, value)
-	case reflect.Int32:
-		return setIntField(val, 32, value)
-	case reflect.Int64:
-		switch value.Interface().(type) {
-		case time.Duration:
-			return setTimeDuration(val, value)
-		}
-		return setIntField(val, 64, value)
-	case reflect.Uint:
-		return setUintField(val, 0, value)
-	case reflect.Uint8:
-		return setUintField(val, 8, value)
-	case reflect.Uint16:
-		return setUintField(val, 16, value)
-	case reflect.Uint32:
-		return setUintField(val, 32, value)
-	case reflect.Uint64:
-		return setUintField(val, 64, value)
-	case reflect.Bool:
-		return setBoolField(val, value)
-	case reflect.Float32:
-		return setFloatField(val, 32, value)
-	case reflect.Float64:
-		return setFloatField(val, 64, value)
-	case reflect.String:
-		value.SetString(val)
-	case reflect.Struct:
-		switch value.Interface().(type) {
-		case time.Time:
+// setFunc converts val and stores it into value.
+type setFunc func(val string, value reflect.Value, field reflect.StructField) error
+
+var (
+	timeType     = reflect.TypeOf(time.Time{})
+	durationType = reflect.TypeOf(time.Duration(0))
+)
+
+var kindSetFuncs = map[reflect.Kind]setFunc{
+	reflect.Int:     intSetFunc(0),
+	reflect.Int8:    intSetFunc(8),
+	reflect.Int16:   intSetFunc(16),
+	reflect.Int32:   intSetFunc(32),
+	reflect.Int64:   intSetFunc(64),
+	reflect.Uint:    uintSetFunc(0),
+	reflect.Uint8:   uintSetFunc(8),
+	reflect.Uint16:  uintSetFunc(16),
+	reflect.Uint32:  uintSetFunc(32),
+	reflect.Uint64:  uintSetFunc(64),
+	reflect.Bool:    func(val string, value reflect.Value, _ reflect.StructField) error { return setBoolField(val, value) },
+	reflect.Float32: floatSetFunc(32),
+	reflect.Float64: floatSetFunc(64),
+	reflect.String:  func(val string, value reflect.Value, _ reflect.StructField) error { value.SetString(val); return nil },
+	reflect.Struct:  setJSONField,
+	reflect.Map:     setJSONField,
+}
+
+// setFuncOf resolves the conversion function for values of type t.
+func setFuncOf(t reflect.Type) setFunc {
+	if set, ok := customSetFunc(t); ok {
+		return set
+	}
+	if t.Kind() == reflect.Ptr {
+		return ptrSetFunc(setFuncOf(t.Elem()))
+	}
+	switch t {
+	case timeType:
+		return func(val string, value reflect.Value, field reflect.StructField) error {
 			return setTimeField(val, field, value)
 		}
-		return json.Unmarshal(bytesconv.StringToBytes(val), value.Addr().Interface())
-	case reflect.Map:
-		return json.Unmarshal(bytesconv.StringToBytes(val), value.Addr().Interface())
-	default:
+	case durationType:
+		return func(val string, value reflect.Value, _ reflect.StructField) error {
+			return setTimeDuration(val, value)
+		}
+	}
+	if set, ok := kindSetFuncs[t.Kind()]; ok {
+		return set
+	}
+	return func(string, reflect.Value, reflect.StructField) error {
 		return errUnknownType
 	}
-	return nil
+}
+
+func setWithProperType(val string, value reflect.Value, field reflect.StructField) error {
+	return setFuncOf(value.Type())(val, value, field)
+}
+
+// ptrSetFunc allocates the value a nil pointer points to, e.g. for the
+// elements of a []*T field.
+func ptrSetFunc(set setFunc) setFunc {
+	return func(val string, value reflect.Value, field reflect.StructField) error {
+		if value.IsNil() {
+			value.Set(reflect.New(value.Type().Elem()))
+		}
+		return set(val, value.Elem(), field)
+	}
+}
+
+func intSetFunc(bitSize int) setFunc {
+	return func(val string, value reflect.Value, _ reflect.StructField) error {
+		return setIntField(val, bitSize, value)
+	}
+}
+
+func uintSetFunc(bitSize int) setFunc {
+	return func(val string, value reflect.Value, _ reflect.StructField) error {
+		return setUintField(val, bitSize, value)
+	}
+}
+
+func floatSetFunc(bitSize int) setFunc {
+	return func(val string, value reflect.Value, _ reflect.StructField) error {
+		return setFloatField(val, bitSize, value)
+	}
+}
+
+func setJSONField(val string, value reflect.Value, _ reflect.StructField) error {
+	return json.Unmarshal(bytesconv.StringToBytes(val), value.Addr().Interface())
 }
 
 func setIntField(val string, bitSize int, field reflect.Value) error {
@@ -339,19 +612,21 @@ func setTimeField(val string, structField reflect.StructField, value reflect.Val
 	return nil
 }
 
-func setArray(vals []string, value reflect.Value, field reflect.StructField) error {
+func setArray(vals []string, value reflect.Value, field reflect.StructField, set setFunc) error {
 	for i, s := range vals {
-		err := setWithProperType(s, value.Index(i), field)
+		err := set(s, value.Index(i), field)
 		if err != nil {
-			return err
+			errs := newFieldError(field, "", s, err)
+			errs[0].Field += "[" + strconv.Itoa(i) + "]"
+			return errs
 		}
 	}
 	return nil
 }
 
-func setSlice(vals []string, value reflect.Value, field reflect.StructField) error {
+func setSlice(vals []string, value reflect.Value, field reflect.StructField, set setFunc) error {
 	slice := reflect.MakeSlice(value.Type(), len(vals), len(vals))
-	err := setArray(vals, slice, field)
+	err := setArray(vals, slice, field, set)
 	if err != nil {
 		return err
 	}
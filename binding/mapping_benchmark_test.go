@@ -0,0 +1,72 @@
+package binding
+
+import (
+	"net/http"
+	"testing"
+	"time"
+)
+
+type benchmarkStruct struct {
+	ID       int           `form:"id" uri:"id" header:"X-Id"`
+	Name     string        `form:"name" uri:"name" header:"X-Name"`
+	Tags     []string      `form:"tags" uri:"tags" header:"X-Tags"`
+	Page     int           `form:"page,default=1" uri:"page,default=1" header:"X-Page,default=1"`
+	Active   bool          `form:"active" uri:"active" header:"X-Active"`
+	Score    float64       `form:"score" uri:"score" header:"X-Score"`
+	Timeout  time.Duration `form:"timeout" uri:"timeout" header:"X-Timeout"`
+	Nested   benchmarkNested
+	Optional *benchmarkNested
+}
+
+type benchmarkNested struct {
+	Lang  string `form:"lang" uri:"lang" header:"X-Lang"`
+	Limit uint   `form:"limit" uri:"limit" header:"X-Limit"`
+}
+
+var benchmarkForm = map[string][]string{
+	"id":      {"42"},
+	"name":    {"rum"},
+	"tags":    {"a", "b", "c"},
+	"active":  {"true"},
+	"score":   {"9.5"},
+	"timeout": {"3s"},
+	"lang":    {"go"},
+	"limit":   {"10"},
+}
+
+func BenchmarkMapForm(b *testing.B) {
+	b.ReportAllocs()
+	for i := 0; i < b.N; i++ {
+		var obj benchmarkStruct
+		if err := mapForm(&obj, benchmarkForm); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
+
+func BenchmarkMapURI(b *testing.B) {
+	b.ReportAllocs()
+	for i := 0; i < b.N; i++ {
+		var obj benchmarkStruct
+		if err := mapURI(&obj, benchmarkForm); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
+
+func BenchmarkMapHeader(b *testing.B) {
+	h := http.Header{}
+	for k, vs := range benchmarkForm {
+		for _, v := range vs {
+			h.Add("X-"+k, v)
+		}
+	}
+	b.ReportAllocs()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		var obj benchmarkStruct
+		if err := mapHeader(&obj, h); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
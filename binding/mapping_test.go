@@ -0,0 +1,364 @@
+// Copyright 2019 Gin Core Team.  All rights reserved.
+// Use of this source code is governed by a MIT style
+// license that can be found in the LICENSE file.
+
+package binding
+
+import (
+	"errors"
+	"net"
+	"net/url"
+	"reflect"
+	"strconv"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestMappingBaseTypes(t *testing.T) {
+	intPtr := func(i int) *int {
+		return &i
+	}
+	for _, tt := range []struct {
+		name   string
+		value  interface{}
+		form   string
+		expect interface{}
+	}{
+		{"base type", struct{ F int }{}, "9", int(9)},
+		{"base type", struct{ F int8 }{}, "9", int8(9)},
+		{"base type", struct{ F int16 }{}, "9", int16(9)},
+		{"base type", struct{ F int32 }{}, "9", int32(9)},
+		{"base type", struct{ F int64 }{}, "9", int64(9)},
+		{"base type", struct{ F uint }{}, "9", uint(9)},
+		{"base type", struct{ F uint8 }{}, "9", uint8(9)},
+		{"base type", struct{ F uint16 }{}, "9", uint16(9)},
+		{"base type", struct{ F uint32 }{}, "9", uint32(9)},
+		{"base type", struct{ F uint64 }{}, "9", uint64(9)},
+		{"base type", struct{ F bool }{}, "True", true},
+		{"base type", struct{ F float32 }{}, "9.1", float32(9.1)},
+		{"base type", struct{ F float64 }{}, "9.1", float64(9.1)},
+		{"base type", struct{ F string }{}, "test", string("test")},
+		{"base type", struct{ F *int }{}, "9", intPtr(9)},
+
+		// zero values
+		{"zero value", struct{ F int }{}, "", int(0)},
+		{"zero value", struct{ F uint }{}, "", uint(0)},
+		{"zero value", struct{ F bool }{}, "", false},
+		{"zero value", struct{ F float32 }{}, "", float32(0)},
+	} {
+		tp := reflect.TypeOf(tt.value)
+		testName := tt.name + ":" + tp.Field(0).Type.String()
+
+		val := reflect.New(reflect.TypeOf(tt.value))
+		val.Elem().Set(reflect.ValueOf(tt.value))
+
+		field := val.Elem().Type().Field(0)
+
+		_, err := mapping(val, formSource{field.Name: {tt.form}}, "form")
+		assert.NoError(t, err, testName)
+
+		actual := val.Elem().Field(0).Interface()
+		assert.Equal(t, tt.expect, actual, testName)
+	}
+}
+
+func TestMappingDefault(t *testing.T) {
+	var s struct {
+		Str   string        `form:",default=defaultVal"`
+		Int   int           `form:",default=9"`
+		Slice []int         `form:",default=9"`
+		Array [1]int        `form:",default=9"`
+		Dur   time.Duration `form:",default=1m"`
+	}
+	err := mappingByPtr(&s, formSource{}, "form")
+	assert.NoError(t, err)
+
+	assert.Equal(t, "defaultVal", s.Str)
+	assert.Equal(t, 9, s.Int)
+	assert.Equal(t, []int{9}, s.Slice)
+	assert.Equal(t, [1]int{9}, s.Array)
+	assert.Equal(t, time.Minute, s.Dur)
+}
+
+func TestMappingSkipField(t *testing.T) {
+	var s struct {
+		A int
+		B int `form:"-"`
+	}
+	err := mappingByPtr(&s, formSource{"A": {"1"}, "B": {"2"}}, "form")
+	assert.NoError(t, err)
+
+	assert.Equal(t, 1, s.A)
+	assert.Equal(t, 0, s.B)
+}
+
+func TestMappingIgnoredCircularRef(t *testing.T) {
+	type S struct {
+		S *S `form:"-"`
+	}
+	var s S
+
+	err := mappingByPtr(&s, formSource{}, "form")
+	assert.NoError(t, err)
+}
+
+func TestMappingStructField(t *testing.T) {
+	var s struct {
+		J struct {
+			I int
+		}
+		Embedded
+		P *struct {
+			Lang string `form:"lang"`
+		}
+	}
+
+	err := mappingByPtr(&s, formSource{"J": {`{"I": 9}`}, "Value": {"embedded"}}, "form")
+	assert.NoError(t, err)
+	assert.Equal(t, 9, s.J.I)
+	assert.Equal(t, "embedded", s.Value)
+	assert.Nil(t, s.P)
+
+	err = mappingByPtr(&s, formSource{"lang": {"go"}}, "form")
+	assert.NoError(t, err)
+	if assert.NotNil(t, s.P) {
+		assert.Equal(t, "go", s.P.Lang)
+	}
+}
+
+type Embedded struct {
+	Value string
+}
+
+func TestMappingUnknownFieldType(t *testing.T) {
+	var s struct {
+		U uintptr
+	}
+
+	err := mappingByPtr(&s, formSource{"U": {"unknown"}}, "form")
+	assert.Error(t, err)
+	assert.True(t, errors.Is(err, errUnknownType))
+}
+
+func TestMappingPlanCache(t *testing.T) {
+	type cached struct {
+		ID   int    `form:"id" uri:"uid"`
+		Name string `form:"name"`
+	}
+
+	var wg sync.WaitGroup
+	for i := 0; i < 8; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			var s cached
+			assert.NoError(t, mapForm(&s, map[string][]string{"id": {"1"}, "name": {"rum"}}))
+			assert.Equal(t, cached{1, "rum"}, s)
+		}()
+	}
+	wg.Wait()
+
+	plan, ok := planCache.Load(planKey{reflect.TypeOf(&cached{}), "form"})
+	if assert.True(t, ok) {
+		fields := plan.(*fieldPlan).sub.fields
+		assert.Len(t, fields, 2)
+		assert.Equal(t, "id", fields[0].key)
+	}
+
+	var s cached
+	assert.NoError(t, mapURI(&s, map[string][]string{"uid": {"2"}}))
+	assert.Equal(t, 2, s.ID)
+}
+
+type customUnmarshalParam struct {
+	Parts []string
+}
+
+func (c *customUnmarshalParam) UnmarshalParam(param string) error {
+	c.Parts = strings.Split(param, ":")
+	return nil
+}
+
+type upperText string
+
+func (u *upperText) UnmarshalText(text []byte) error {
+	if len(text) == 0 || text[0] == '!' {
+		return errors.New("invalid text")
+	}
+	*u = upperText(strings.ToUpper(string(text)))
+	return nil
+}
+
+func TestMappingBindUnmarshaler(t *testing.T) {
+	var s struct {
+		Param  customUnmarshalParam   `form:"param"`
+		PParam *customUnmarshalParam  `form:"pparam"`
+		Params []customUnmarshalParam `form:"params"`
+	}
+	err := mappingByPtr(&s, formSource{
+		"param":  {"a:b"},
+		"pparam": {"c:d"},
+		"params": {"e:f", "g"},
+	}, "form")
+	assert.NoError(t, err)
+
+	assert.Equal(t, []string{"a", "b"}, s.Param.Parts)
+	assert.Equal(t, []string{"c", "d"}, s.PParam.Parts)
+	assert.Equal(t, []customUnmarshalParam{{[]string{"e", "f"}}, {[]string{"g"}}}, s.Params)
+}
+
+func TestMappingTextUnmarshaler(t *testing.T) {
+	var s struct {
+		IP    net.IP       `form:"ip"`
+		Text  upperText    `form:"text"`
+		Texts []*upperText `form:"texts"`
+		Empty upperText    `form:"empty"`
+	}
+	err := mappingByPtr(&s, formSource{
+		"ip":    {"192.168.1.1"},
+		"text":  {"rum"},
+		"texts": {"a", "b"},
+		"empty": {""},
+	}, "form")
+	assert.NoError(t, err)
+
+	assert.Equal(t, "192.168.1.1", s.IP.String())
+	assert.Equal(t, upperText("RUM"), s.Text)
+	if assert.Len(t, s.Texts, 2) {
+		assert.Equal(t, upperText("B"), *s.Texts[1])
+	}
+	assert.Equal(t, upperText(""), s.Empty)
+
+	err = mappingByPtr(&s, formSource{"text": {"!bad"}}, "form")
+	assert.Error(t, err)
+}
+
+func TestMappingRegisterDecoder(t *testing.T) {
+	type cents int64
+	var s struct {
+		URL   url.URL `form:"url"`
+		Price cents   `form:"price"`
+	}
+	// compile the plan before registering the decoders
+	assert.Error(t, mappingByPtr(&s, formSource{"price": {"1.50"}}, "form"))
+
+	RegisterDecoder(reflect.TypeOf(url.URL{}), func(val string) (interface{}, error) {
+		u, err := url.Parse(val)
+		if err != nil {
+			return nil, err
+		}
+		return *u, nil
+	})
+	RegisterDecoder(reflect.TypeOf(cents(0)), func(val string) (interface{}, error) {
+		f, err := strconv.ParseFloat(val, 64)
+		return int64(f * 100), err
+	})
+	defer func() {
+		decodersMu.Lock()
+		delete(decoders, reflect.TypeOf(url.URL{}))
+		delete(decoders, reflect.TypeOf(cents(0)))
+		decodersMu.Unlock()
+	}()
+
+	err := mappingByPtr(&s, formSource{"url": {"https://example.com/a?b=c"}, "price": {"1.50"}}, "form")
+	assert.NoError(t, err)
+	assert.Equal(t, "example.com", s.URL.Host)
+	assert.Equal(t, cents(150), s.Price)
+
+	err = mappingByPtr(&s, formSource{"url": {"%zz"}}, "form")
+	assert.Error(t, err)
+}
+
+func TestMappingCollectionFormat(t *testing.T) {
+	var s struct {
+		Multi []int    `form:"multi,collection_format=multi"`
+		CSV   []int    `form:"csv,collection_format=csv"`
+		SSV   []string `form:"ssv,collection_format=ssv"`
+		TSV   []string `form:"tsv,collection_format=tsv"`
+		Pipes []int    `form:"pipes,collection_format=pipes"`
+		Array [2]int   `form:"array,collection_format=csv"`
+		Def   []int    `form:"def,default=5,collection_format=csv"`
+	}
+	err := mappingByPtr(&s, formSource{
+		"multi": {"1", "2"},
+		"csv":   {"1,2", "3"},
+		"ssv":   {"a b"},
+		"tsv":   {"a\tb"},
+		"pipes": {"1|2|3"},
+		"array": {"1,2"},
+	}, "form")
+	assert.NoError(t, err)
+
+	assert.Equal(t, []int{1, 2}, s.Multi)
+	assert.Equal(t, []int{1, 2, 3}, s.CSV)
+	assert.Equal(t, []string{"a", "b"}, s.SSV)
+	assert.Equal(t, []string{"a", "b"}, s.TSV)
+	assert.Equal(t, []int{1, 2, 3}, s.Pipes)
+	assert.Equal(t, [2]int{1, 2}, s.Array)
+	assert.Equal(t, []int{5}, s.Def)
+
+	err = mappingByPtr(&s, formSource{"csv": {"1,x"}}, "form")
+	var errs Errors
+	if assert.True(t, errors.As(err, &errs)) {
+		assert.Equal(t, "CSV[1]", errs[0].Field)
+		assert.Equal(t, "csv", errs[0].Key)
+	}
+
+	var bad struct {
+		Ints []int `form:"ints,collection_format=xxx"`
+	}
+	err = mappingByPtr(&bad, formSource{"ints": {"1"}}, "form")
+	assert.Error(t, err)
+}
+
+func TestMappingDeepObject(t *testing.T) {
+	type address struct {
+		City string `form:"city"`
+		Zip  int    `form:"zip"`
+	}
+	var s struct {
+		Filter struct {
+			Name    string   `form:"name"`
+			Tags    []string `form:"tags"`
+			Address address  `form:"address"`
+		} `form:"filter"`
+		Labels map[string]string `form:"labels"`
+		Counts map[string][]int  `form:"counts"`
+		Ptr    *address          `form:"ptr"`
+	}
+	err := mappingByPtr(&s, formSource{
+		"filter[name]":          {"rum"},
+		"filter[tags]":          {"a", "b"},
+		"filter[address][city]": {"Paris"},
+		"labels[env]":           {"prod"},
+		"labels[team]":          {"web"},
+		"counts[a]":             {"1", "2"},
+		"ptr[zip]":              {"75001"},
+	}, "form")
+	assert.NoError(t, err)
+
+	assert.Equal(t, "rum", s.Filter.Name)
+	assert.Equal(t, []string{"a", "b"}, s.Filter.Tags)
+	assert.Equal(t, "Paris", s.Filter.Address.City)
+	assert.Equal(t, map[string]string{"env": "prod", "team": "web"}, s.Labels)
+	assert.Equal(t, map[string][]int{"a": {1, 2}}, s.Counts)
+	if assert.NotNil(t, s.Ptr) {
+		assert.Equal(t, 75001, s.Ptr.Zip)
+	}
+
+	err = mappingByPtr(&s, formSource{"filter[address][zip]": {"x"}}, "form")
+	var errs Errors
+	if assert.True(t, errors.As(err, &errs)) {
+		assert.Equal(t, "Filter.Address.Zip", errs[0].Field)
+		assert.Equal(t, "filter[address][zip]", errs[0].Key)
+	}
+
+	err = mappingByPtr(&s, formSource{"counts[b]": {"x"}}, "form")
+	if assert.True(t, errors.As(err, &errs)) {
+		assert.Equal(t, "Counts[b]", errs[0].Field)
+	}
+}
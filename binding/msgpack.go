@@ -0,0 +1,43 @@
+// Copyright 2017 Manu Martinez-Almeida.  All rights reserved.
+// Use of this source code is governed by a MIT style
+// license that can be found in the LICENSE file.
+
+package binding
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"io"
+	"net/http"
+
+	"github.com/ugorji/go/codec"
+)
+
+type msgpackBinding struct{}
+
+func (msgpackBinding) Name() string {
+	return "msgpack"
+}
+
+func (msgpackBinding) Bind(req *http.Request, obj interface{}) error {
+	if req == nil || req.Body == nil {
+		return errors.New("invalid request")
+	}
+	if err := decodeMsgPack(req.Body, obj); err != nil {
+		return err
+	}
+	return validate(req.Context(), obj, "msgpack")
+}
+
+func (msgpackBinding) BindBody(body []byte, obj interface{}) error {
+	if err := decodeMsgPack(bytes.NewReader(body), obj); err != nil {
+		return err
+	}
+	return validate(context.Background(), obj, "msgpack")
+}
+
+func decodeMsgPack(r io.Reader, obj interface{}) error {
+	cdc := new(codec.MsgpackHandle)
+	return codec.NewDecoder(r, cdc).Decode(&obj)
+}
@@ -14,8 +14,8 @@ func (queryBinding) Name() string {
 
 func (queryBinding) Bind(req *http.Request, obj interface{}) error {
 	values := req.URL.Query()
-	if err := mapForm(obj, values); err != nil {
+	if err := mapQuery(obj, values); err != nil {
 		return err
 	}
-	return validate(obj)
+	return validate(req.Context(), obj, "query")
 }
@@ -0,0 +1,43 @@
+// Copyright 2022 Gin Core Team. All rights reserved.
+// Use of this source code is governed by a MIT style
+// license that can be found in the LICENSE file.
+
+package binding
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"io"
+	"net/http"
+
+	"github.com/pelletier/go-toml/v2"
+)
+
+type tomlBinding struct{}
+
+func (tomlBinding) Name() string {
+	return "toml"
+}
+
+func (tomlBinding) Bind(req *http.Request, obj interface{}) error {
+	if req == nil || req.Body == nil {
+		return errors.New("invalid request")
+	}
+	if err := decodeToml(req.Body, obj); err != nil {
+		return err
+	}
+	return validate(req.Context(), obj, "toml")
+}
+
+func (tomlBinding) BindBody(body []byte, obj interface{}) error {
+	if err := decodeToml(bytes.NewReader(body), obj); err != nil {
+		return err
+	}
+	return validate(context.Background(), obj, "toml")
+}
+
+func decodeToml(r io.Reader, obj interface{}) error {
+	decoder := toml.NewDecoder(r)
+	return decoder.Decode(obj)
+}
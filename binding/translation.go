@@ -0,0 +1,211 @@
+package binding
+
+import (
+	"errors"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+
+	"github.com/go-playground/validator/v10"
+)
+
+// DefaultLanguage is the language of the messages when none of the languages
+// accepted by the client has messages registered.
+var DefaultLanguage = "en"
+
+const (
+	// messageDefault is the template used for a validation tag without a
+	// registered message.
+	messageDefault = "default"
+	// messageConversion is the template used when a value can not be
+	// converted to the type of its field.
+	messageConversion = "conversion"
+)
+
+var (
+	messagesMu sync.RWMutex
+	messages   = map[string]map[string]string{
+		"en": {
+			messageDefault:    "{field} failed on the '{tag}' rule",
+			messageConversion: "{field} has an invalid value '{value}'",
+			"required":        "{field} is a required field",
+			"len":             "{field} must have a length of {param}",
+			"min":             "{field} must be at least {param}",
+			"max":             "{field} must be at most {param}",
+			"eq":              "{field} must be equal to {param}",
+			"ne":              "{field} must not be equal to {param}",
+			"lt":              "{field} must be less than {param}",
+			"lte":             "{field} must be less than or equal to {param}",
+			"gt":              "{field} must be greater than {param}",
+			"gte":             "{field} must be greater than or equal to {param}",
+			"oneof":           "{field} must be one of [{param}]",
+			"email":           "{field} must be a valid email address",
+			"url":             "{field} must be a valid URL",
+			"uri":             "{field} must be a valid URI",
+			"uuid":            "{field} must be a valid UUID",
+			"numeric":         "{field} must be a valid numeric value",
+			"number":          "{field} must be a valid number",
+			"alpha":           "{field} can only contain alphabetic characters",
+			"alphanum":        "{field} can only contain alphanumeric characters",
+			"boolean":         "{field} must be a valid boolean value",
+			"datetime":        "{field} does not match the {param} format",
+			"ip":              "{field} must be a valid IP address",
+			"ipv4":            "{field} must be a valid IPv4 address",
+			"ipv6":            "{field} must be a valid IPv6 address",
+			"contains":        "{field} must contain the text '{param}'",
+			"excludes":        "{field} cannot contain the text '{param}'",
+			"startswith":      "{field} must start with '{param}'",
+			"endswith":        "{field} must end with '{param}'",
+			"unique":          "{field} must contain unique values",
+			"eqfield":         "{field} must be equal to {param}",
+			"nefield":         "{field} cannot be equal to {param}",
+			"max_size":        "{field} must be at most {param}",
+			"mime":            "{field} must be of type {param}",
+		},
+		"zh": {
+			messageDefault:    "{field}未通过'{tag}'校验",
+			messageConversion: "{field}的值'{value}'无效",
+			"required":        "{field}为必填字段",
+			"len":             "{field}长度必须是{param}",
+			"min":             "{field}最小只能为{param}",
+			"max":             "{field}最大只能为{param}",
+			"eq":              "{field}必须等于{param}",
+			"ne":              "{field}不能等于{param}",
+			"lt":              "{field}必须小于{param}",
+			"lte":             "{field}必须小于或等于{param}",
+			"gt":              "{field}必须大于{param}",
+			"gte":             "{field}必须大于或等于{param}",
+			"oneof":           "{field}必须是[{param}]中的一个",
+			"email":           "{field}必须是一个有效的邮箱",
+			"url":             "{field}必须是一个有效的URL",
+			"uri":             "{field}必须是一个有效的URI",
+			"uuid":            "{field}必须是一个有效的UUID",
+			"numeric":         "{field}必须是一个有效的数值",
+			"number":          "{field}必须是一个有效的数字",
+			"alpha":           "{field}只能包含字母",
+			"alphanum":        "{field}只能包含字母和数字",
+			"boolean":         "{field}必须是一个有效的布尔值",
+			"datetime":        "{field}的格式必须是{param}",
+			"ip":              "{field}必须是一个有效的IP地址",
+			"ipv4":            "{field}必须是一个有效的IPv4地址",
+			"ipv6":            "{field}必须是一个有效的IPv6地址",
+			"contains":        "{field}必须包含文本'{param}'",
+			"excludes":        "{field}不能包含文本'{param}'",
+			"startswith":      "{field}必须以'{param}'开头",
+			"endswith":        "{field}必须以'{param}'结尾",
+			"unique":          "{field}必须包含唯一的值",
+			"eqfield":         "{field}必须等于{param}",
+			"nefield":         "{field}不能等于{param}",
+			"max_size":        "{field}不能超过{param}",
+			"mime":            "{field}的类型必须是{param}",
+		},
+	}
+)
+
+// RegisterMessage registers the message template of a validation tag, such as
+// "required" or a custom one, for the given language, e.g. "en" or "zh". The
+// template may use the placeholders {field} (the key of the field in its
+// source, e.g. its json or form name), {param}, {value} and {tag}.
+func RegisterMessage(lang, tag, template string) {
+	lang = strings.ToLower(lang)
+	messagesMu.Lock()
+	defer messagesMu.Unlock()
+	if messages[lang] == nil {
+		messages[lang] = make(map[string]string)
+	}
+	messages[lang][tag] = template
+}
+
+// Translate renders a message for every field error found in err, in the
+// language that best matches acceptLanguage, the value of an Accept-Language
+// header. It returns nil if err holds no field errors.
+func Translate(err error, acceptLanguage string) Errors {
+	var errs Errors
+	if !errors.As(err, &errs) {
+		return nil
+	}
+	lang := negotiateLanguage(acceptLanguage)
+
+	messagesMu.RLock()
+	defer messagesMu.RUnlock()
+	for _, e := range errs {
+		e.Message = renderMessage(lang, e)
+	}
+	return errs
+}
+
+func renderMessage(lang string, e *FieldError) string {
+	tag, param := messageConversion, ""
+	var fe validator.FieldError
+	if errors.As(e.Err, &fe) {
+		tag, param = fe.Tag(), fe.Param()
+	}
+	template := lookupMessage(lang, tag)
+	field := e.Key
+	if field == "" {
+		field = e.Field
+	}
+	return strings.NewReplacer(
+		"{field}", field,
+		"{param}", param,
+		"{value}", e.Value,
+		"{tag}", tag,
+	).Replace(template)
+}
+
+// lookupMessage finds the template of tag in lang, falling back to the
+// template of the default language and then to the generic template.
+func lookupMessage(lang, tag string) string {
+	for _, key := range []string{tag, messageDefault} {
+		for _, l := range []string{lang, DefaultLanguage} {
+			if template, ok := messages[l][key]; ok {
+				return template
+			}
+		}
+	}
+	return ""
+}
+
+// negotiateLanguage picks the registered language preferred by the client.
+func negotiateLanguage(acceptLanguage string) string {
+	type weighted struct {
+		lang string
+		q    float64
+	}
+	var langs []weighted
+	for _, part := range strings.Split(acceptLanguage, ",") {
+		tag, params := head(strings.TrimSpace(part), ";")
+		if tag == "" || tag == "*" {
+			continue
+		}
+		q := 1.0
+		if k, v := head(strings.TrimSpace(params), "="); k == "q" {
+			if f, err := strconv.ParseFloat(v, 64); err == nil {
+				q = f
+			}
+		}
+		langs = append(langs, weighted{strings.ToLower(tag), q})
+	}
+	sort.SliceStable(langs, func(i, j int) bool {
+		return langs[i].q > langs[j].q
+	})
+
+	messagesMu.RLock()
+	defer messagesMu.RUnlock()
+	for _, l := range langs {
+		if l.q <= 0 {
+			continue
+		}
+		if _, ok := messages[l.lang]; ok {
+			return l.lang
+		}
+		// fall back to the primary subtag, e.g. zh-CN -> zh
+		if primary, _ := head(l.lang, "-"); primary != l.lang {
+			if _, ok := messages[primary]; ok {
+				return primary
+			}
+		}
+	}
+	return DefaultLanguage
+}
@@ -0,0 +1,75 @@
+package binding
+
+import (
+	"net/http"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+type translationStruct struct {
+	UserName string `form:"user_name" json:"user_name" binding:"required"`
+	Age      int    `form:"age" json:"age" binding:"gte=18"`
+	Level    string `form:"level" binding:"oneof=low high"`
+}
+
+func TestTranslateEnglish(t *testing.T) {
+	req, _ := http.NewRequest(http.MethodGet, "/?age=3&level=mid", nil)
+	var obj translationStruct
+	errs := Translate(Query.Bind(req, &obj), "en-US,en;q=0.9")
+	if assert.Len(t, errs, 3) {
+		assert.Equal(t, "user_name is a required field", errs[0].Message)
+		assert.Equal(t, "age must be greater than or equal to 18", errs[1].Message)
+		assert.Equal(t, "level must be one of [low high]", errs[2].Message)
+	}
+}
+
+func TestTranslateChinese(t *testing.T) {
+	req, _ := http.NewRequest(http.MethodGet, "/?age=3&level=low", nil)
+	var obj translationStruct
+	errs := Translate(Query.Bind(req, &obj), "fr;q=0.9, zh-CN")
+	if assert.Len(t, errs, 2) {
+		assert.Equal(t, "user_name为必填字段", errs[0].Message)
+		assert.Equal(t, "age必须大于或等于18", errs[1].Message)
+	}
+}
+
+func TestTranslateConversionAndFallback(t *testing.T) {
+	req, _ := http.NewRequest(http.MethodGet, "/?age=abc", nil)
+	var obj translationStruct
+	errs := Translate(Query.Bind(req, &obj), "de")
+	if assert.Len(t, errs, 1) {
+		assert.Equal(t, "age has an invalid value 'abc'", errs[0].Message)
+	}
+
+	assert.Nil(t, Translate(nil, "en"))
+}
+
+func TestRegisterMessage(t *testing.T) {
+	RegisterMessage("en", "gte", "{field} is too small, minimum {param}")
+	defer RegisterMessage("en", "gte", "{field} must be greater than or equal to {param}")
+	RegisterMessage("fr", "required", "{field} est obligatoire")
+	defer func() {
+		messagesMu.Lock()
+		delete(messages, "fr")
+		messagesMu.Unlock()
+	}()
+
+	req, _ := http.NewRequest(http.MethodGet, "/?age=3&level=low", nil)
+	var obj translationStruct
+	err := Query.Bind(req, &obj)
+	errs := Translate(err, "en")
+	assert.Equal(t, "age is too small, minimum 18", errs[1].Message)
+
+	errs = Translate(err, "fr-FR")
+	assert.Equal(t, "user_name est obligatoire", errs[0].Message)
+	assert.Equal(t, "age is too small, minimum 18", errs[1].Message)
+}
+
+func TestNegotiateLanguage(t *testing.T) {
+	assert.Equal(t, "en", negotiateLanguage(""))
+	assert.Equal(t, "zh", negotiateLanguage("zh-TW"))
+	assert.Equal(t, "en", negotiateLanguage("zh;q=0, en;q=0.5"))
+	assert.Equal(t, "zh", negotiateLanguage("en;q=0.4, zh;q=0.8"))
+	assert.Equal(t, "en", negotiateLanguage("*"))
+}
@@ -4,6 +4,8 @@
 
 package binding
 
+import "context"
+
 type uriBinding struct{}
 
 func (uriBinding) Name() string {
@@ -14,5 +16,5 @@ func (uriBinding) BindUri(m map[string][]string, obj interface{}) error {
 	if err := mapURI(obj, m); err != nil {
 		return err
 	}
-	return validate(obj)
+	return validate(context.Background(), obj, "uri")
 }
@@ -0,0 +1,103 @@
+package binding
+
+import (
+	"bytes"
+	"context"
+	"errors"
+
+	"github.com/go-playground/validator/v10"
+)
+
+var errUnsupportedValidator = errors.New("binding: Validator is not backed by go-playground/validator")
+
+// validatorEngine returns the engine of Validator, if it is the go-playground
+// validator used by the default Validator.
+func validatorEngine() (*validator.Validate, error) {
+	if Validator == nil {
+		return nil, errUnsupportedValidator
+	}
+	v, ok := Validator.Engine().(*validator.Validate)
+	if !ok {
+		return nil, errUnsupportedValidator
+	}
+	return v, nil
+}
+
+// RegisterValidation adds a validation with the given tag, which can then be
+// used in the "binding" struct tag, e.g. `binding:"required,is-awesome"`.
+func RegisterValidation(tag string, fn validator.Func, callValidationEvenIfNull ...bool) error {
+	v, err := validatorEngine()
+	if err != nil {
+		return err
+	}
+	return v.RegisterValidation(tag, fn, callValidationEvenIfNull...)
+}
+
+// RegisterValidationCtx is like RegisterValidation, but fn receives the
+// context of the request being bound. Within a rum handler, rum.ContextFrom
+// retrieves the current *rum.Context from it until the request is handled.
+func RegisterValidationCtx(tag string, fn validator.FuncCtx, callValidationEvenIfNull ...bool) error {
+	v, err := validatorEngine()
+	if err != nil {
+		return err
+	}
+	return v.RegisterValidationCtx(tag, fn, callValidationEvenIfNull...)
+}
+
+// RegisterStructValidation registers a struct level validation for the
+// types of the given values.
+func RegisterStructValidation(fn validator.StructLevelFunc, types ...interface{}) error {
+	v, err := validatorEngine()
+	if err != nil {
+		return err
+	}
+	v.RegisterStructValidation(fn, types...)
+	return nil
+}
+
+// RegisterStructValidationCtx is like RegisterStructValidation, but fn
+// receives the context of the request being bound.
+func RegisterStructValidationCtx(fn validator.StructLevelFuncCtx, types ...interface{}) error {
+	v, err := validatorEngine()
+	if err != nil {
+		return err
+	}
+	v.RegisterStructValidationCtx(fn, types...)
+	return nil
+}
+
+// RegisterAlias registers alias as a shorthand for tags, e.g.
+// RegisterAlias("iscolor", "hexcolor|rgb|rgba|hsl|hsla").
+func RegisterAlias(alias, tags string) error {
+	v, err := validatorEngine()
+	if err != nil {
+		return err
+	}
+	v.RegisterAlias(alias, tags)
+	return nil
+}
+
+// BindBodyContext is like bb.BindBody, but the validation runs with ctx.
+// Bindings other than the ones of this package ignore ctx.
+func BindBodyContext(ctx context.Context, bb BindingBody, body []byte, obj interface{}) error {
+	decode, ok := bodyDecoder(bb)
+	if _, protobuf := bb.(protobufBinding); !ok || protobuf {
+		return bb.BindBody(body, obj)
+	}
+	if err := decode(bytes.NewReader(body), obj); err != nil {
+		return err
+	}
+	return validate(ctx, obj, bb.Name())
+}
+
+// BindUriContext is like b.BindUri, but the validation runs with ctx.
+// Bindings other than Uri ignore ctx.
+func BindUriContext(ctx context.Context, b BindingUri, m map[string][]string, obj interface{}) error {
+	if b != Uri {
+		return b.BindUri(m, obj)
+	}
+	if err := mapURI(obj, m); err != nil {
+		return err
+	}
+	return validate(ctx, obj, "uri")
+}
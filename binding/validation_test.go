@@ -0,0 +1,121 @@
+package binding
+
+import (
+	"bytes"
+	"context"
+	"net/http"
+	"testing"
+
+	"github.com/go-playground/validator/v10"
+	"github.com/stretchr/testify/assert"
+)
+
+type validationKey struct{}
+
+func TestRegisterValidation(t *testing.T) {
+	assert.NoError(t, RegisterValidation("is_rum", func(fl validator.FieldLevel) bool {
+		return fl.Field().String() == "rum"
+	}))
+
+	var obj struct {
+		Name string `form:"name" binding:"is_rum"`
+	}
+	req, _ := http.NewRequest(http.MethodGet, "/?name=rum", nil)
+	assert.NoError(t, Query.Bind(req, &obj))
+
+	req, _ = http.NewRequest(http.MethodGet, "/?name=gin", nil)
+	err := Query.Bind(req, &obj)
+	assert.Equal(t, "field Name (query \"name\"): is_rum", err.Error())
+}
+
+func TestRegisterAlias(t *testing.T) {
+	assert.NoError(t, RegisterAlias("short_name", "min=2,max=4"))
+
+	var obj struct {
+		Name string `form:"name" binding:"short_name"`
+	}
+	req, _ := http.NewRequest(http.MethodGet, "/?name=abcdef", nil)
+	assert.Error(t, Query.Bind(req, &obj))
+	req, _ = http.NewRequest(http.MethodGet, "/?name=abc", nil)
+	assert.NoError(t, Query.Bind(req, &obj))
+}
+
+type validationRange struct {
+	From int `json:"from"`
+	To   int `json:"to"`
+}
+
+func TestRegisterStructValidation(t *testing.T) {
+	assert.NoError(t, RegisterStructValidation(func(sl validator.StructLevel) {
+		r := sl.Current().Interface().(validationRange)
+		if r.From > r.To {
+			sl.ReportError(r.To, "To", "To", "gtefield", "From")
+		}
+	}, validationRange{}))
+
+	var obj validationRange
+	assert.NoError(t, JSON.BindBody([]byte(`{"from": 1, "to": 2}`), &obj))
+	err := JSON.BindBody([]byte(`{"from": 3, "to": 2}`), &obj)
+	assert.Equal(t, "field To (json \"to\"): gtefield=From", err.Error())
+}
+
+type validationTenant struct {
+	Plan string `json:"plan" uri:"plan" binding:"tenant_plan"`
+}
+
+func TestRegisterValidationCtx(t *testing.T) {
+	assert.NoError(t, RegisterValidationCtx("tenant_plan", func(ctx context.Context, fl validator.FieldLevel) bool {
+		allowed, _ := ctx.Value(validationKey{}).(string)
+		return fl.Field().String() == allowed
+	}))
+	ctx := context.WithValue(context.Background(), validationKey{}, "pro")
+
+	var obj validationTenant
+	req, _ := http.NewRequest(http.MethodPost, "/", bytes.NewBufferString(`{"plan": "pro"}`))
+	assert.NoError(t, JSON.Bind(req.WithContext(ctx), &obj))
+	req, _ = http.NewRequest(http.MethodPost, "/", bytes.NewBufferString(`{"plan": "pro"}`))
+	assert.Error(t, JSON.Bind(req, &obj))
+
+	assert.NoError(t, BindBodyContext(ctx, JSON, []byte(`{"plan": "pro"}`), &obj))
+	assert.Error(t, BindBodyContext(ctx, JSON, []byte(`{"plan": "free"}`), &obj))
+	assert.Error(t, BindBodyContext(ctx, JSON, []byte(`{`), &obj))
+
+	assert.NoError(t, BindUriContext(ctx, Uri, map[string][]string{"plan": {"pro"}}, &obj))
+	assert.Error(t, BindUriContext(ctx, Uri, map[string][]string{"plan": {"free"}}, &obj))
+}
+
+func TestRegisterStructValidationCtx(t *testing.T) {
+	type quota struct {
+		Count int `json:"count"`
+	}
+	assert.NoError(t, RegisterStructValidationCtx(func(ctx context.Context, sl validator.StructLevel) {
+		limit, _ := ctx.Value(validationKey{}).(int)
+		if sl.Current().Interface().(quota).Count > limit {
+			sl.ReportError(0, "Count", "Count", "quota", "")
+		}
+	}, quota{}))
+
+	ctx := context.WithValue(context.Background(), validationKey{}, 10)
+	var obj quota
+	assert.NoError(t, BindBodyContext(ctx, JSON, []byte(`{"count": 5}`), &obj))
+	assert.Error(t, BindBodyContext(ctx, JSON, []byte(`{"count": 50}`), &obj))
+}
+
+type validationNoEngine struct{}
+
+func (validationNoEngine) ValidateStruct(interface{}) error { return nil }
+func (validationNoEngine) Engine() interface{}              { return nil }
+
+func TestRegisterValidationUnsupported(t *testing.T) {
+	defer func(v StructValidator) { Validator = v }(Validator)
+	Validator = validationNoEngine{}
+
+	assert.Equal(t, errUnsupportedValidator, RegisterValidation("x", nil))
+	assert.Equal(t, errUnsupportedValidator, RegisterValidationCtx("x", nil))
+	assert.Equal(t, errUnsupportedValidator, RegisterStructValidation(nil))
+	assert.Equal(t, errUnsupportedValidator, RegisterStructValidationCtx(nil))
+	assert.Equal(t, errUnsupportedValidator, RegisterAlias("x", "required"))
+
+	Validator = nil
+	assert.Equal(t, errUnsupportedValidator, RegisterAlias("x", "required"))
+}
@@ -0,0 +1,42 @@
+// Copyright 2014 Manu Martinez-Almeida.  All rights reserved.
+// Use of this source code is governed by a MIT style
+// license that can be found in the LICENSE file.
+
+package binding
+
+import (
+	"bytes"
+	"context"
+	"encoding/xml"
+	"errors"
+	"io"
+	"net/http"
+)
+
+type xmlBinding struct{}
+
+func (xmlBinding) Name() string {
+	return "xml"
+}
+
+func (xmlBinding) Bind(req *http.Request, obj interface{}) error {
+	if req == nil || req.Body == nil {
+		return errors.New("invalid request")
+	}
+	if err := decodeXML(req.Body, obj); err != nil {
+		return err
+	}
+	return validate(req.Context(), obj, "xml")
+}
+
+func (xmlBinding) BindBody(body []byte, obj interface{}) error {
+	if err := decodeXML(bytes.NewReader(body), obj); err != nil {
+		return err
+	}
+	return validate(context.Background(), obj, "xml")
+}
+
+func decodeXML(r io.Reader, obj interface{}) error {
+	decoder := xml.NewDecoder(r)
+	return decoder.Decode(obj)
+}
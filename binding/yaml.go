@@ -0,0 +1,43 @@
+// Copyright 2018 Gin Core Team.  All rights reserved.
+// Use of this source code is governed by a MIT style
+// license that can be found in the LICENSE file.
+
+package binding
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"io"
+	"net/http"
+
+	"gopkg.in/yaml.v2"
+)
+
+type yamlBinding struct{}
+
+func (yamlBinding) Name() string {
+	return "yaml"
+}
+
+func (yamlBinding) Bind(req *http.Request, obj interface{}) error {
+	if req == nil || req.Body == nil {
+		return errors.New("invalid request")
+	}
+	if err := decodeYAML(req.Body, obj); err != nil {
+		return err
+	}
+	return validate(req.Context(), obj, "yaml")
+}
+
+func (yamlBinding) BindBody(body []byte, obj interface{}) error {
+	if err := decodeYAML(bytes.NewReader(body), obj); err != nil {
+		return err
+	}
+	return validate(context.Background(), obj, "yaml")
+}
+
+func decodeYAML(r io.Reader, obj interface{}) error {
+	decoder := yaml.NewDecoder(r)
+	return decoder.Decode(obj)
+}
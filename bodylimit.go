@@ -0,0 +1,78 @@
+package rum
+
+import (
+	"errors"
+	"io"
+	"net/http"
+)
+
+// ErrBodyTooLarge is returned when reading a request body larger than the
+// limit set by Engine.MaxBodyBytes or MaxBodyBytes.
+var ErrBodyTooLarge = errors.New("http: request body too large")
+
+// limitedBody enforces the body limit of a request with http.MaxBytesReader
+// and remembers whether the limit was exceeded.
+type limitedBody struct {
+	io.ReadCloser
+
+	// raw is the original body, used when a route changes the limit
+	raw           io.ReadCloser
+	contentLength int64
+	limit         int64
+	read          int64
+	exceeded      bool
+}
+
+func (b *limitedBody) Read(p []byte) (int, error) {
+	if b.exceeded {
+		return 0, ErrBodyTooLarge
+	}
+	// no need to read a body announced larger than the limit
+	if b.contentLength > b.limit {
+		b.exceeded = true
+		return 0, ErrBodyTooLarge
+	}
+	n, err := b.ReadCloser.Read(p)
+	b.read += int64(n)
+	// the body may also wrap a limited body, see Decompress
+	if err != nil && err != io.EOF && (b.read >= b.limit || err == ErrBodyTooLarge) {
+		b.exceeded = true
+		err = ErrBodyTooLarge
+	}
+	return n, err
+}
+
+// limitBody limits the request body of c to n bytes. A limit set before the
+// body was read is replaced.
+func (c *Context) limitBody(n int64) {
+	raw := c.Request.Body
+	if c.body != nil && c.Request.Body == c.body && c.body.read == 0 && !c.body.exceeded {
+		raw = c.body.raw
+	}
+	if raw == nil || raw == http.NoBody {
+		return
+	}
+	c.body = &limitedBody{
+		ReadCloser:    http.MaxBytesReader(c.writermem.ResponseWriter, raw, n),
+		raw:           raw,
+		contentLength: c.Request.ContentLength,
+		limit:         n,
+	}
+	c.Request.Body = c.body
+}
+
+// bodyTooLarge reports whether reading the request body exceeded its limit.
+func (c *Context) bodyTooLarge() bool {
+	return c.body != nil && c.body.exceeded
+}
+
+// MaxBodyBytes returns a middleware limiting the request body of the routes
+// using it to n bytes, replacing the limit set by Engine.MaxBodyBytes.
+// Reading a larger body fails with ErrBodyTooLarge and the request is
+// answered with 413 unless the handlers wrote another response.
+func MaxBodyBytes(n int64) HandlerFunc {
+	return func(c *Context) {
+		c.limitBody(n)
+		c.Next()
+	}
+}
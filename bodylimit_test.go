@@ -0,0 +1,151 @@
+package rum
+
+import (
+	"bytes"
+	"io/ioutil"
+	"mime/multipart"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/MichaelDeSteven/rum/binding"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestEngineMaxBodyBytes(t *testing.T) {
+	router := New(":9678")
+	router.MaxBodyBytes = 16
+	var err error
+	router.POST("/should", func(c *Context) {
+		var obj struct {
+			Name string `json:"name"`
+		}
+		err = c.ShouldBindJSON(&obj)
+	})
+	router.POST("/bind", func(c *Context) {
+		var obj map[string]interface{}
+		c.BindJSON(&obj)
+	})
+
+	w := PerformRequest(router, "POST", "/should", withBody([]byte(`{"name": "rum"}`)))
+	assert.NoError(t, err)
+	assert.Equal(t, http.StatusOK, w.Code)
+
+	// chunked body, the limit is hit while reading
+	w = PerformRequest(router, "POST", "/should", withBody([]byte(`{"name": "a long name"}`)))
+	assert.ErrorIs(t, err, ErrBodyTooLarge)
+	assert.Equal(t, http.StatusRequestEntityTooLarge, w.Code)
+
+	// announced body, rejected before reading
+	w = PerformRequest(router, "POST", "/should", withBody([]byte(`{"name": "a long name"}`)), withRequest(func(req *http.Request) {
+		req.ContentLength = 24
+	}))
+	assert.ErrorIs(t, err, ErrBodyTooLarge)
+	assert.Equal(t, http.StatusRequestEntityTooLarge, w.Code)
+
+	w = PerformRequest(router, "POST", "/bind", withBody([]byte(`{"name": "a long name"}`)))
+	assert.Equal(t, http.StatusRequestEntityTooLarge, w.Code)
+}
+
+func TestMaxBodyBytesPerRoute(t *testing.T) {
+	router := New(":9678")
+	router.MaxBodyBytes = 8
+	var body []byte
+	var err error
+	read := func(c *Context) {
+		body, err = ioutil.ReadAll(c.Request.Body)
+	}
+	router.POST("/large", MaxBodyBytes(64), read)
+	router.POST("/small", MaxBodyBytes(4), read)
+	router.POST("/written", MaxBodyBytes(4), func(c *Context) {
+		read(c)
+		c.String(http.StatusBadRequest, "too much")
+	})
+
+	w := PerformRequest(router, "POST", "/large", withBody([]byte("0123456789")))
+	assert.NoError(t, err)
+	assert.Equal(t, "0123456789", string(body))
+	assert.Equal(t, http.StatusOK, w.Code)
+
+	w = PerformRequest(router, "POST", "/small", withBody([]byte("0123456")))
+	assert.ErrorIs(t, err, ErrBodyTooLarge)
+	assert.Equal(t, http.StatusRequestEntityTooLarge, w.Code)
+
+	w = PerformRequest(router, "POST", "/written", withBody([]byte("0123456")))
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+	assert.Equal(t, "too much", w.Body.String())
+}
+
+func TestMaxBodyBytesBindBodyWith(t *testing.T) {
+	router := New(":9678")
+	var err error
+	router.POST("/", MaxBodyBytes(8), func(c *Context) {
+		var obj map[string]interface{}
+		err = c.ShouldBindBodyWith(&obj, binding.JSON)
+	})
+
+	PerformRequest(router, "POST", "/", withBody([]byte(`{"name": "rum"}`)))
+	assert.ErrorIs(t, err, ErrBodyTooLarge)
+}
+
+type endlessReader struct{}
+
+func (endlessReader) Read(p []byte) (int, error) {
+	for i := range p {
+		p[i] = ' '
+	}
+	return len(p), nil
+}
+
+func TestBindBodyWithDefaultLimit(t *testing.T) {
+	router := New(":9678")
+	var err error
+	router.POST("/", func(c *Context) {
+		var obj map[string]interface{}
+		err = c.ShouldBindBodyWith(&obj, binding.JSON)
+	})
+
+	req := httptest.NewRequest("POST", "/", ioutil.NopCloser(endlessReader{}))
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+	assert.ErrorIs(t, err, ErrBodyTooLarge)
+	assert.Equal(t, http.StatusRequestEntityTooLarge, w.Code)
+
+	w = PerformRequest(router, "POST", "/", withBody([]byte(`{"name": "rum"}`)))
+	assert.NoError(t, err)
+	assert.Equal(t, http.StatusOK, w.Code)
+}
+
+func TestEngineMaxMultipartMemory(t *testing.T) {
+	router := New(":9678")
+	assert.Equal(t, int64(defaultMultipartMemory), router.MaxMultipartMemory)
+	router.MaxMultipartMemory = 4
+
+	buf := new(bytes.Buffer)
+	mw := multipart.NewWriter(buf)
+	w, _ := mw.CreateFormFile("file", "test.txt")
+	w.Write([]byte("0123456789"))
+	mw.WriteField("name", "rum")
+	mw.Close()
+
+	var name string
+	var content []byte
+	router.POST("/", func(c *Context) {
+		var obj struct {
+			Name string `form:"name"`
+		}
+		assert.NoError(t, c.ShouldBind(&obj))
+		name = obj.Name
+		fh, err := c.FormFile("file")
+		if assert.NoError(t, err) {
+			f, _ := fh.Open()
+			content, _ = ioutil.ReadAll(f)
+			f.Close()
+		}
+	})
+	req := httptest.NewRequest("POST", "/", buf)
+	req.Header.Set("Content-Type", mw.FormDataContentType())
+	router.ServeHTTP(httptest.NewRecorder(), req)
+	assert.Equal(t, "rum", name)
+	assert.Equal(t, "0123456789", string(content))
+}
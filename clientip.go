@@ -0,0 +1,118 @@
+package rum
+
+import (
+	"fmt"
+	"net"
+	"strings"
+)
+
+// The headers set with the client IP by some platforms, see
+// Engine.TrustedPlatform.
+const (
+	PlatformCloudflare      = "CF-Connecting-IP"
+	PlatformGoogleAppEngine = "X-Appengine-Remote-Addr"
+	PlatformFlyIO           = "Fly-Client-IP"
+)
+
+// SetTrustedProxies sets the proxies whose headers Context.ClientIP reads
+// the client IP from, as IP addresses or CIDR networks, e.g. "10.0.0.0/8".
+// No proxy is trusted by default, nor when proxies is nil.
+func (e *Engine) SetTrustedProxies(proxies []string) error {
+	cidrs := make([]*net.IPNet, 0, len(proxies))
+	for _, proxy := range proxies {
+		proxy = strings.TrimSpace(proxy)
+		if !strings.Contains(proxy, "/") {
+			ip := net.ParseIP(proxy)
+			if ip == nil {
+				return fmt.Errorf("invalid trusted proxy %q", proxy)
+			}
+			bits := net.IPv6len * 8
+			if ip4 := ip.To4(); ip4 != nil {
+				ip, bits = ip4, net.IPv4len*8
+			}
+			cidrs = append(cidrs, &net.IPNet{IP: ip, Mask: net.CIDRMask(bits, bits)})
+			continue
+		}
+		_, cidr, err := net.ParseCIDR(proxy)
+		if err != nil {
+			return fmt.Errorf("invalid trusted proxy %q: %v", proxy, err)
+		}
+		cidrs = append(cidrs, cidr)
+	}
+	e.trustedCIDRs = cidrs
+	return nil
+}
+
+func (e *Engine) isTrustedProxy(ip net.IP) bool {
+	for _, cidr := range e.trustedCIDRs {
+		if cidr.Contains(ip) {
+			return true
+		}
+	}
+	return false
+}
+
+// RemoteIP returns the IP address of the peer of the connection, from
+// Request.RemoteAddr, or "" if it is not an IP address.
+func (c *Context) RemoteIP() string {
+	host, _, err := net.SplitHostPort(strings.TrimSpace(c.Request.RemoteAddr))
+	if err != nil {
+		host = strings.TrimSpace(c.Request.RemoteAddr)
+	}
+	if ip := net.ParseIP(host); ip != nil {
+		return ip.String()
+	}
+	return ""
+}
+
+// ClientIP returns the IP address of the client. It is read from the
+// Engine.TrustedPlatform header if set, then from the Engine.RemoteIPHeaders
+// when the request comes from a trusted proxy, see SetTrustedProxies, and is
+// the remote IP otherwise.
+//
+// X-Forwarded-For is read from right to left, each proxy appending the
+// address of its peer: the client IP is the first address which is not a
+// trusted proxy, the addresses on its left may be forged by the client.
+func (c *Context) ClientIP() string {
+	e := c.engine
+	if e == nil {
+		return c.RemoteIP()
+	}
+	if e.TrustedPlatform != "" {
+		if ip := net.ParseIP(strings.TrimSpace(c.requestHeader(e.TrustedPlatform))); ip != nil {
+			return ip.String()
+		}
+	}
+
+	remoteIP := c.RemoteIP()
+	if remoteIP == "" || !e.isTrustedProxy(net.ParseIP(remoteIP)) {
+		return remoteIP
+	}
+	for _, name := range e.RemoteIPHeaders {
+		// a proxy may append its own line rather than extend the client's
+		header := strings.Join(c.Request.Header.Values(name), ",")
+		if ip, ok := e.forwardedIP(header); ok {
+			return ip
+		}
+	}
+	return remoteIP
+}
+
+// forwardedIP returns the client IP of a header listing the addresses of the
+// client and of the proxies, or false if the header is missing or invalid.
+func (e *Engine) forwardedIP(header string) (string, bool) {
+	if header == "" {
+		return "", false
+	}
+	addrs := strings.Split(header, ",")
+	for i := len(addrs) - 1; i >= 0; i-- {
+		ip := net.ParseIP(strings.TrimSpace(addrs[i]))
+		if ip == nil {
+			return "", false
+		}
+		if i == 0 || !e.isTrustedProxy(ip) {
+			return ip.String(), true
+		}
+	}
+	return "", false
+}
@@ -0,0 +1,114 @@
+package rum
+
+import (
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func clientIPRouter(t *testing.T, proxies []string) (*Engine, *string) {
+	router := New(":9678")
+	assert.NoError(t, router.SetTrustedProxies(proxies))
+	ip := new(string)
+	router.GET("/", func(c *Context) {
+		*ip = c.ClientIP()
+	})
+	return router, ip
+}
+
+func TestClientIP(t *testing.T) {
+	router, ip := clientIPRouter(t, []string{"10.0.0.0/8", "192.168.1.1", "fd00::/8"})
+
+	for _, test := range []struct {
+		remoteAddr string
+		headers    []header
+		expected   string
+	}{
+		{"203.0.113.7:1234", nil, "203.0.113.7"},
+		{"[2001:db8::1]:1234", nil, "2001:db8::1"},
+		// the headers of untrusted peers are ignored
+		{"203.0.113.7:1234", []header{{"X-Forwarded-For", "1.2.3.4"}}, "203.0.113.7"},
+		{"10.0.0.1:1234", []header{{"X-Forwarded-For", "1.2.3.4"}}, "1.2.3.4"},
+		{"192.168.1.1:1234", []header{{"X-Forwarded-For", "1.2.3.4"}}, "1.2.3.4"},
+		{"192.168.1.2:1234", []header{{"X-Forwarded-For", "1.2.3.4"}}, "192.168.1.2"},
+		// the entries on the left of the client may be forged
+		{"10.0.0.1:1234", []header{{"X-Forwarded-For", "6.6.6.6, 1.2.3.4, 10.1.1.1 , 10.2.2.2"}}, "1.2.3.4"},
+		{"10.0.0.1:1234", []header{{"X-Forwarded-For", "10.3.3.3,10.2.2.2"}}, "10.3.3.3"},
+		{"[fd00::1]:1234", []header{{"X-Forwarded-For", "2001:db8::2, fd00::3"}}, "2001:db8::2"},
+		// the lines of a header are a single list
+		{"10.0.0.1:1234", []header{{"X-Forwarded-For", "6.6.6.6"}, {"X-Forwarded-For", "1.2.3.4"}}, "1.2.3.4"},
+		{"10.0.0.1:1234", []header{{"X-Forwarded-For", "6.6.6.6, 1.2.3.4"}, {"X-Forwarded-For", "10.2.2.2"}}, "1.2.3.4"},
+		// an invalid entry makes the header invalid
+		{"10.0.0.1:1234", []header{{"X-Forwarded-For", "1.2.3.4, unknown"}, {"X-Real-IP", "5.6.7.8"}}, "5.6.7.8"},
+		{"10.0.0.1:1234", []header{{"X-Forwarded-For", "1.2.3.4:80"}}, "10.0.0.1"},
+		{"10.0.0.1:1234", []header{{"X-Real-IP", " 5.6.7.8 "}}, "5.6.7.8"},
+		// X-Forwarded-For comes first
+		{"10.0.0.1:1234", []header{{"X-Real-IP", "5.6.7.8"}, {"X-Forwarded-For", "1.2.3.4"}}, "1.2.3.4"},
+		{"not an address", []header{{"X-Forwarded-For", "1.2.3.4"}}, ""},
+	} {
+		options := []requestOption{withRemoteAddr(test.remoteAddr)}
+		for _, h := range test.headers {
+			options = append(options, h)
+		}
+		PerformRequest(router, "GET", "/", options...)
+		assert.Equal(t, test.expected, *ip, "%s %v", test.remoteAddr, test.headers)
+	}
+
+	router.RemoteIPHeaders = []string{"X-Real-IP"}
+	PerformRequest(router, "GET", "/", withRemoteAddr("10.0.0.1:1234"), header{"X-Forwarded-For", "1.2.3.4"}, header{"X-Real-IP", "5.6.7.8"})
+	assert.Equal(t, "5.6.7.8", *ip)
+}
+
+func TestClientIPTrustedPlatform(t *testing.T) {
+	router, ip := clientIPRouter(t, nil)
+	router.TrustedPlatform = PlatformCloudflare
+
+	PerformRequest(router, "GET", "/", withRemoteAddr("203.0.113.7:1234"), header{"CF-Connecting-IP", "1.2.3.4"}, header{"X-Forwarded-For", "5.6.7.8"})
+	assert.Equal(t, "1.2.3.4", *ip)
+
+	PerformRequest(router, "GET", "/", withRemoteAddr("203.0.113.7:1234"), header{"CF-Connecting-IP", "invalid"})
+	assert.Equal(t, "203.0.113.7", *ip)
+}
+
+func TestSetTrustedProxies(t *testing.T) {
+	router := New(":9678")
+	assert.NoError(t, router.SetTrustedProxies([]string{"127.0.0.1", "::1", "172.16.0.0/12"}))
+	assert.Len(t, router.trustedCIDRs, 3)
+	assert.Error(t, router.SetTrustedProxies([]string{"localhost"}))
+	assert.Error(t, router.SetTrustedProxies([]string{"10.0.0.0/33"}))
+
+	// no proxy is trusted by default
+	router, ip := clientIPRouter(t, nil)
+	PerformRequest(router, "GET", "/", withRemoteAddr("127.0.0.1:1234"), header{"X-Forwarded-For", "1.2.3.4"})
+	assert.Equal(t, "127.0.0.1", *ip)
+}
+
+func TestRemoteIP(t *testing.T) {
+	c, _ := CreateTestContext(nil)
+	c.Request, _ = http.NewRequest("GET", "/", nil)
+	for addr, expected := range map[string]string{
+		"10.0.0.1:1234":       "10.0.0.1",
+		"[::1]:80":            "::1",
+		"10.0.0.1":            "10.0.0.1",
+		"example.com:80":      "",
+		"@":                   "",
+		"[::ffff:1.2.3.4]:80": "1.2.3.4",
+	} {
+		c.Request.RemoteAddr = addr
+		assert.Equal(t, expected, c.RemoteIP(), addr)
+	}
+}
+
+func TestRateLimitBehindProxy(t *testing.T) {
+	router := New(":9678")
+	assert.NoError(t, router.SetTrustedProxies([]string{"10.0.0.0/8"}))
+	router.Use(RateLimit(RateLimitConfig{Algorithm: NewTokenBucket(1, time.Hour, 0)}))
+	router.GET("/", func(c *Context) {})
+
+	assert.Equal(t, http.StatusOK, PerformRequest(router, "GET", "/", withRemoteAddr("10.0.0.1:1"), header{"X-Forwarded-For", "1.2.3.4"}).Code)
+	assert.Equal(t, http.StatusOK, PerformRequest(router, "GET", "/", withRemoteAddr("10.0.0.1:1"), header{"X-Forwarded-For", "5.6.7.8"}).Code)
+	assert.Equal(t, http.StatusTooManyRequests, PerformRequest(router, "GET", "/", withRemoteAddr("10.0.0.2:1"), header{"X-Forwarded-For", "6.6.6.6, 1.2.3.4"}).Code)
+	assert.Equal(t, http.StatusTooManyRequests, PerformRequest(router, "GET", "/", withRemoteAddr("10.0.0.2:1"), header{"X-Forwarded-For", "7.7.7.7"}, header{"X-Forwarded-For", "5.6.7.8"}).Code)
+}
@@ -0,0 +1,331 @@
+package rum
+
+import (
+	"compress/gzip"
+	"compress/zlib"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"strconv"
+	"strings"
+	"sync"
+)
+
+// DefaultCompressMinLength is the default length below which the response
+// bodies are not compressed.
+const DefaultCompressMinLength = 1024
+
+// DefaultExcludedContentTypes are the content types not compressed by default,
+// because they are already compressed. The entries ending with "/" match all
+// the subtypes.
+var DefaultExcludedContentTypes = []string{
+	"image/", "video/", "audio/",
+	"font/woff", "font/woff2",
+	"application/zip", "application/gzip", "application/x-gzip",
+	"application/x-bzip2", "application/x-xz", "application/zstd",
+	"application/x-7z-compressed", "application/x-rar-compressed",
+}
+
+// Encoder compresses a response body, like gzip.Writer. Reset makes it write
+// to w as a new encoder, so that it can be reused.
+type Encoder interface {
+	io.WriteCloser
+	Flush() error
+	Reset(w io.Writer)
+}
+
+// EncoderFunc returns an Encoder writing to w at the given compression level.
+type EncoderFunc func(w io.Writer, level int) (Encoder, error)
+
+// defaultEncoders are the content codings Compress always supports.
+var defaultEncoders = map[string]EncoderFunc{
+	"gzip": func(w io.Writer, level int) (Encoder, error) {
+		return gzip.NewWriterLevel(w, level)
+	},
+	// the deflate content coding is the zlib format, see RFC 9110 8.4.1.2
+	"deflate": func(w io.Writer, level int) (Encoder, error) {
+		return zlib.NewWriterLevel(w, level)
+	},
+}
+
+// CompressConfig configures the Compress middleware.
+type CompressConfig struct {
+	// Encodings lists the content codings in order of preference, "gzip"
+	// and "deflate" when empty.
+	Encodings []string
+
+	// Encoders adds the encoders of other content codings, such as "br",
+	// or replaces those of "gzip" and "deflate". The added codings must be
+	// listed in Encodings to be used.
+	Encoders map[string]EncoderFunc
+
+	// Level is the compression level given to the encoders,
+	// gzip.DefaultCompression when nil.
+	Level *int
+
+	// MinLength is the length below which the bodies are sent uncompressed,
+	// DefaultCompressMinLength when 0.
+	MinLength int
+
+	// ExcludedContentTypes lists the content types sent uncompressed,
+	// DefaultExcludedContentTypes when nil.
+	ExcludedContentTypes []string
+
+	// ExcludedPaths lists the path prefixes whose responses are sent
+	// uncompressed.
+	ExcludedPaths []string
+}
+
+type compressor struct {
+	cfg   CompressConfig
+	pools map[string]*sync.Pool
+}
+
+// Compress returns a middleware compressing the response bodies with the
+// content coding negotiated with the Accept-Encoding header of the request.
+// The response is buffered until MinLength bytes are written, so that the
+// short bodies are sent uncompressed, or until the handler flushes it. The
+// bodies already encoded, partial or of an excluded content type are sent
+// as is.
+func Compress(cfg CompressConfig) HandlerFunc {
+	if len(cfg.Encodings) == 0 {
+		cfg.Encodings = []string{"gzip", "deflate"}
+	}
+	level := gzip.DefaultCompression
+	if cfg.Level != nil {
+		level = *cfg.Level
+	}
+	if cfg.MinLength == 0 {
+		cfg.MinLength = DefaultCompressMinLength
+	}
+	if cfg.ExcludedContentTypes == nil {
+		cfg.ExcludedContentTypes = DefaultExcludedContentTypes
+	}
+
+	encoders := make(map[string]EncoderFunc, len(defaultEncoders)+len(cfg.Encoders))
+	for encoding, fn := range defaultEncoders {
+		encoders[encoding] = fn
+	}
+	for encoding, fn := range cfg.Encoders {
+		encoders[strings.ToLower(encoding)] = fn
+	}
+
+	cp := &compressor{cfg: cfg, pools: make(map[string]*sync.Pool)}
+	cp.cfg.Encodings = make([]string, len(cfg.Encodings))
+	for i, encoding := range cfg.Encodings {
+		encoding = strings.ToLower(encoding)
+		cp.cfg.Encodings[i] = encoding
+		fn, ok := encoders[encoding]
+		assert1(ok, "no encoder registered for "+encoding)
+		_, err := fn(ioutil.Discard, level)
+		assert1(err == nil, "invalid compression level for "+encoding)
+		cp.pools[encoding] = &sync.Pool{New: func() interface{} {
+			enc, _ := fn(ioutil.Discard, level)
+			return enc
+		}}
+	}
+
+	return func(c *Context) {
+		if c.Method == http.MethodHead || cp.excludedPath(c.Path) {
+			c.Next()
+			return
+		}
+		c.Writer.Header().Add("Vary", "Accept-Encoding")
+		encoding := negotiateEncoding(c.requestHeader("Accept-Encoding"), cp.cfg.Encodings)
+		if encoding == "" {
+			c.Next()
+			return
+		}
+
+		w := &compressWriter{ResponseWriter: c.Writer, cp: cp, encoding: encoding}
+		c.Writer = w
+		c.Next()
+		w.close()
+		c.Writer = w.ResponseWriter
+	}
+}
+
+func (cp *compressor) excludedPath(path string) bool {
+	for _, prefix := range cp.cfg.ExcludedPaths {
+		if strings.HasPrefix(path, prefix) {
+			return true
+		}
+	}
+	return false
+}
+
+func (cp *compressor) excludedContentType(contentType string) bool {
+	contentType = strings.ToLower(filterFlags(contentType))
+	for _, t := range cp.cfg.ExcludedContentTypes {
+		if contentType == t || strings.HasSuffix(t, "/") && strings.HasPrefix(contentType, t) {
+			return true
+		}
+	}
+	return false
+}
+
+// negotiateEncoding returns the content coding of encodings with the highest
+// quality in the Accept-Encoding header, the first one in case of a tie, or
+// "" when none is acceptable.
+func negotiateEncoding(accept string, encodings []string) string {
+	if accept == "" {
+		return ""
+	}
+	qualities := make(map[string]float64)
+	for _, part := range strings.Split(accept, ",") {
+		params := strings.Split(part, ";")
+		name := strings.ToLower(strings.TrimSpace(params[0]))
+		q := 1.0
+		for _, p := range params[1:] {
+			p = strings.TrimSpace(p)
+			if strings.HasPrefix(p, "q=") {
+				if v, err := strconv.ParseFloat(p[2:], 64); err == nil {
+					q = v
+				}
+			}
+		}
+		if name != "" {
+			qualities[name] = q
+		}
+	}
+
+	best, bestQ := "", 0.0
+	for _, encoding := range encodings {
+		q, ok := qualities[encoding]
+		if !ok {
+			q = qualities["*"]
+		}
+		if q > bestQ {
+			best, bestQ = encoding, q
+		}
+	}
+	return best
+}
+
+// compressWriter buffers the beginning of the body until it can decide
+// whether to compress it.
+type compressWriter struct {
+	ResponseWriter
+	cp       *compressor
+	encoding string
+
+	buf     []byte
+	decided bool
+	enc     Encoder
+}
+
+var _ ResponseWriter = &compressWriter{}
+
+// compressible reports whether the response can be compressed given its
+// status and headers.
+func (w *compressWriter) compressible() bool {
+	status := w.Status()
+	if status < http.StatusOK || status == http.StatusNoContent || status == http.StatusNotModified ||
+		status == http.StatusPartialContent || w.ResponseWriter.Written() {
+		return false
+	}
+	h := w.Header()
+	if h.Get("Content-Encoding") != "" || h.Get("Content-Range") != "" ||
+		w.cp.excludedContentType(h.Get("Content-Type")) {
+		return false
+	}
+	if n, err := strconv.Atoi(h.Get("Content-Length")); err == nil && n < w.cp.cfg.MinLength {
+		return false
+	}
+	return true
+}
+
+// start writes the buffered bytes, compressed or not.
+func (w *compressWriter) start(compress bool) error {
+	w.decided = true
+	if compress {
+		h := w.Header()
+		if h.Get("Content-Type") == "" {
+			// the underlying writer would sniff the compressed bytes
+			h.Set("Content-Type", http.DetectContentType(w.buf))
+		}
+		h.Set("Content-Encoding", w.encoding)
+		h.Del("Content-Length")
+		w.enc = w.cp.pools[w.encoding].Get().(Encoder)
+		w.enc.Reset(w.ResponseWriter)
+	}
+	buf := w.buf
+	w.buf = nil
+	if len(buf) == 0 {
+		return nil
+	}
+	_, err := w.write(buf)
+	return err
+}
+
+func (w *compressWriter) write(data []byte) (int, error) {
+	if w.enc != nil {
+		return w.enc.Write(data)
+	}
+	return w.ResponseWriter.Write(data)
+}
+
+func (w *compressWriter) Write(data []byte) (int, error) {
+	if !w.decided {
+		if !w.compressible() {
+			w.start(false)
+			return w.write(data)
+		}
+		w.buf = append(w.buf, data...)
+		if len(w.buf) < w.cp.cfg.MinLength {
+			return len(data), nil
+		}
+		if err := w.start(true); err != nil {
+			return 0, err
+		}
+		return len(data), nil
+	}
+	return w.write(data)
+}
+
+func (w *compressWriter) WriteString(s string) (int, error) {
+	return w.Write([]byte(s))
+}
+
+// WriteHeader is ignored once the beginning of the body is buffered.
+func (w *compressWriter) WriteHeader(code int) {
+	if !w.Written() {
+		w.ResponseWriter.WriteHeader(code)
+	}
+}
+
+func (w *compressWriter) WriteHeaderNow() {
+	if !w.decided {
+		w.start(false)
+	}
+	w.ResponseWriter.WriteHeaderNow()
+}
+
+func (w *compressWriter) Written() bool {
+	return len(w.buf) > 0 || w.ResponseWriter.Written()
+}
+
+// Flush sends the body written so far, compressing it regardless of its
+// length as the length of a flushed response is unknown.
+func (w *compressWriter) Flush() {
+	if !w.decided {
+		w.start(w.compressible())
+	}
+	if w.enc != nil {
+		w.enc.Flush()
+	}
+	w.ResponseWriter.Flush()
+}
+
+// close sends the rest of the body and returns the encoder to its pool.
+func (w *compressWriter) close() {
+	if !w.decided {
+		w.start(false)
+	}
+	if w.enc != nil {
+		w.enc.Close()
+		w.enc.Reset(ioutil.Discard)
+		w.cp.pools[w.encoding].Put(w.enc)
+		w.enc = nil
+	}
+}
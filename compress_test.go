@@ -0,0 +1,184 @@
+package rum
+
+import (
+	"bytes"
+	"compress/gzip"
+	"compress/zlib"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+var largeBody = strings.Repeat("rum compresses the large bodies. ", 100)
+
+func compressRouter(cfg CompressConfig) *Engine {
+	router := New(":9678")
+	router.Use(Compress(cfg))
+	router.GET("/large", func(c *Context) {
+		c.String(http.StatusOK, largeBody)
+	})
+	router.GET("/small", func(c *Context) {
+		c.String(http.StatusOK, "small")
+	})
+	router.GET("/image", func(c *Context) {
+		c.SetHeader("Content-Type", "image/png")
+		c.Data(http.StatusOK, []byte(largeBody))
+	})
+	router.GET("/encoded", func(c *Context) {
+		c.SetHeader("Content-Encoding", "gzip")
+		c.Data(http.StatusOK, []byte(largeBody))
+	})
+	router.GET("/chunks", func(c *Context) {
+		for i := 0; i < 100; i++ {
+			c.Writer.WriteString("rum compresses the large bodies. ")
+		}
+	})
+	router.GET("/stream", func(c *Context) {
+		c.Writer.WriteString("first")
+		c.Writer.Flush()
+		c.Writer.WriteString("second")
+	})
+	router.GET("/empty", func(c *Context) {
+		c.Status(http.StatusNoContent)
+	})
+	return router
+}
+
+func decompress(t *testing.T, encoding string, body []byte) string {
+	var r io.Reader
+	var err error
+	switch encoding {
+	case "gzip":
+		r, err = gzip.NewReader(bytes.NewReader(body))
+	case "deflate":
+		r, err = zlib.NewReader(bytes.NewReader(body))
+	}
+	assert.NoError(t, err)
+	data, err := ioutil.ReadAll(r)
+	assert.NoError(t, err)
+	return string(data)
+}
+
+func TestCompress(t *testing.T) {
+	router := compressRouter(CompressConfig{ExcludedPaths: []string{"/static"}})
+
+	for _, encoding := range []string{"gzip", "deflate"} {
+		for _, path := range []string{"/large", "/chunks"} {
+			w := PerformRequest(router, "GET", path, header{"Accept-Encoding", encoding})
+			assert.Equal(t, http.StatusOK, w.Code)
+			assert.Equal(t, encoding, w.Header().Get("Content-Encoding"))
+			assert.Equal(t, "Accept-Encoding", w.Header().Get("Vary"))
+			assert.Empty(t, w.Header().Get("Content-Length"))
+			assert.Less(t, w.Body.Len(), len(largeBody))
+			assert.Equal(t, largeBody, decompress(t, encoding, w.Body.Bytes()))
+		}
+	}
+
+	w := PerformRequest(router, "GET", "/chunks", header{"Accept-Encoding", "gzip"})
+	assert.Equal(t, "text/plain; charset=utf-8", w.Header().Get("Content-Type"))
+
+	for _, path := range []string{"/small", "/image", "/encoded"} {
+		w := PerformRequest(router, "GET", path, header{"Accept-Encoding", "deflate"})
+		assert.Equal(t, http.StatusOK, w.Code, path)
+		assert.Equal(t, "Accept-Encoding", w.Header().Get("Vary"), path)
+		assert.NotEqual(t, "deflate", w.Header().Get("Content-Encoding"), path)
+	}
+	assert.Equal(t, "small", PerformRequest(router, "GET", "/small", header{"Accept-Encoding", "gzip"}).Body.String())
+
+	w = PerformRequest(router, "GET", "/large", header{"Accept-Encoding", "br"})
+	assert.Empty(t, w.Header().Get("Content-Encoding"))
+	assert.Equal(t, largeBody, w.Body.String())
+
+	w = PerformRequest(router, "GET", "/empty", header{"Accept-Encoding", "gzip"})
+	assert.Equal(t, http.StatusNoContent, w.Code)
+	assert.Empty(t, w.Header().Get("Content-Encoding"))
+	assert.Empty(t, w.Body.String())
+
+	// the flushed responses are compressed whatever their length
+	w = PerformRequest(router, "GET", "/stream", header{"Accept-Encoding", "gzip"})
+	assert.Equal(t, "gzip", w.Header().Get("Content-Encoding"))
+	assert.Equal(t, "firstsecond", decompress(t, "gzip", w.Body.Bytes()))
+}
+
+func TestCompressExcludedPaths(t *testing.T) {
+	router := New(":9678")
+	router.Use(Compress(CompressConfig{ExcludedPaths: []string{"/static/"}}))
+	router.GET("/static/app.js", func(c *Context) {
+		c.String(http.StatusOK, largeBody)
+	})
+
+	w := PerformRequest(router, "GET", "/static/app.js", header{"Accept-Encoding", "gzip"})
+	assert.Empty(t, w.Header().Get("Content-Encoding"))
+	assert.Empty(t, w.Header().Get("Vary"))
+	assert.Equal(t, largeBody, w.Body.String())
+}
+
+func TestNegotiateEncoding(t *testing.T) {
+	encodings := []string{"gzip", "deflate"}
+	for accept, expected := range map[string]string{
+		"":                          "",
+		"gzip":                      "gzip",
+		"GZIP":                      "gzip",
+		"deflate, gzip":             "gzip",
+		"gzip;q=0.5, deflate":       "deflate",
+		"gzip;q=0, deflate;q=0.1":   "deflate",
+		"gzip;q=0":                  "",
+		"*":                         "gzip",
+		"*;q=0.2, gzip;q=0.1":       "deflate",
+		"br, identity":              "",
+		"br;q=1.0, gzip ; q=0.8, *": "deflate",
+	} {
+		assert.Equal(t, expected, negotiateEncoding(accept, encodings), accept)
+	}
+}
+
+type upperEncoder struct {
+	w io.Writer
+}
+
+func (e *upperEncoder) Write(p []byte) (int, error) {
+	return e.w.Write(bytes.ToUpper(p))
+}
+
+func (e *upperEncoder) Flush() error      { return nil }
+func (e *upperEncoder) Close() error      { return nil }
+func (e *upperEncoder) Reset(w io.Writer) { e.w = w }
+
+func TestCompressEncoders(t *testing.T) {
+	upper := func(w io.Writer, level int) (Encoder, error) {
+		return &upperEncoder{w}, nil
+	}
+	router := compressRouter(CompressConfig{
+		Encodings: []string{"x-upper", "gzip"},
+		Encoders:  map[string]EncoderFunc{"X-Upper": upper},
+		MinLength: 1,
+	})
+	w := PerformRequest(router, "GET", "/small", header{"Accept-Encoding", "gzip, x-upper"})
+	assert.Equal(t, "x-upper", w.Header().Get("Content-Encoding"))
+	assert.Equal(t, "SMALL", w.Body.String())
+
+	assert.Panics(t, func() { Compress(CompressConfig{Encodings: []string{"br"}}) })
+	// the encoders of a middleware are not shared with the others
+	assert.Panics(t, func() { Compress(CompressConfig{Encodings: []string{"x-upper"}}) })
+	invalid := 42
+	assert.Panics(t, func() { Compress(CompressConfig{Level: &invalid}) })
+}
+
+func TestCompressLevel(t *testing.T) {
+	sizes := make(map[int]int)
+	for _, level := range []int{gzip.NoCompression, gzip.BestCompression} {
+		level := level
+		router := compressRouter(CompressConfig{Level: &level})
+		w := PerformRequest(router, "GET", "/large", header{"Accept-Encoding", "gzip"})
+		assert.Equal(t, "gzip", w.Header().Get("Content-Encoding"))
+		assert.Equal(t, largeBody, decompress(t, "gzip", w.Body.Bytes()))
+		sizes[level] = w.Body.Len()
+	}
+	// the stored blocks of NoCompression are larger than the body
+	assert.Greater(t, sizes[gzip.NoCompression], len(largeBody))
+	assert.Less(t, sizes[gzip.BestCompression], len(largeBody)/10)
+}
@@ -1,14 +1,17 @@
 package rum
 
 import (
+	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"io"
 	"io/ioutil"
 	"math"
 	"mime/multipart"
 	"net/http"
-	"os"
+	"net/url"
+	"strconv"
 	"sync"
 
 	"github.com/MichaelDeSteven/rum/binding"
@@ -20,8 +23,13 @@ const BodyKey = "BodyKey"
 // Multipart default size
 const defaultMultipartMemory = 32 << 20 // 32 MB
 
-// Abort inx
-const abortInx = math.MaxInt8 >> 1
+// DefaultBodyWithMaxBytes is the size of the bodies ShouldBindBodyWith reads
+// when neither Engine.MaxBodyBytes nor MaxBodyBytes limit the request body.
+const DefaultBodyWithMaxBytes = 32 << 20 // 32 MB
+
+// abortInx is the index of an aborted context, the handlers chains are
+// limited to fewer handlers, see RouterGroup.combine.
+const abortInx = math.MaxInt16
 
 // Content-Type MIME of the most common data formats.
 const (
@@ -30,6 +38,13 @@ const (
 	MIMEPlain             = binding.MIMEPlain
 	MIMEPOSTForm          = binding.MIMEPOSTForm
 	MIMEMultipartPOSTForm = binding.MIMEMultipartPOSTForm
+	MIMEXML               = binding.MIMEXML
+	MIMEXML2              = binding.MIMEXML2
+	MIMEPROTOBUF          = binding.MIMEPROTOBUF
+	MIMEMSGPACK           = binding.MIMEMSGPACK
+	MIMEMSGPACK2          = binding.MIMEMSGPACK2
+	MIMEYAML              = binding.MIMEYAML
+	MIMETOML              = binding.MIMETOML
 )
 
 type Param struct {
@@ -40,7 +55,11 @@ type Param struct {
 type Params []Param
 
 type Context struct {
-	Writer http.ResponseWriter
+	engine *Engine
+
+	writermem responseWriter
+
+	Writer ResponseWriter
 
 	Request *http.Request
 
@@ -53,11 +72,14 @@ type Context struct {
 	StatusCode int
 
 	// current executes handler index, see Next() function
-	index int8
+	index int
 
 	// handler list
 	HandlersChain
 
+	// fullPath is the path of the matched route, see FullPath
+	fullPath string
+
 	// This mutex protects Keys map.
 	mu sync.RWMutex
 
@@ -66,6 +88,12 @@ type Context struct {
 
 	// Errors is a list of errors attached to all the handlers/middlewares who used this context.
 	Errors errorMsgs
+
+	// body is the limited request body, see Engine.MaxBodyBytes
+	body *limitedBody
+
+	// ref is carried by the context of the request, see bindingContext
+	ref *contextRef
 }
 
 func (ps Params) Get(name string) (string, bool) {
@@ -83,7 +111,18 @@ func (c *Context) Param(name string) string {
 }
 
 func (c *Context) reset(w http.ResponseWriter, r *http.Request) {
-	c.Writer = w
+	c.writermem.reset(w)
+	c.Writer = &c.writermem
+	c.Keys = nil
+	c.Errors = c.Errors[:0]
+	c.body = nil
+	c.ref = nil
+	c.resetRoute(r)
+}
+
+// resetRoute clears the routing state so that the context can be dispatched
+// through the router again, see Engine.HandleContext.
+func (c *Context) resetRoute(r *http.Request) {
 	if r != nil {
 		c.Request = r
 		c.Method = r.Method
@@ -91,23 +130,104 @@ func (c *Context) reset(w http.ResponseWriter, r *http.Request) {
 	}
 	c.Params = c.Params[:0]
 	c.HandlersChain = nil
-	c.Keys = nil
+	c.fullPath = ""
 	c.index = -1
 }
 
+// FullPath returns the path of the matched route, e.g. "/users/:id" for a
+// request to "/users/42". It returns "" when no route matched.
+func (c *Context) FullPath() string {
+	return c.fullPath
+}
+
+// HandlerName returns the main handler's name. For example if the handler is
+// "handleGetUsers()", this function will return "main.handleGetUsers".
+func (c *Context) HandlerName() string {
+	if len(c.HandlersChain) == 0 {
+		return ""
+	}
+	return nameOfFunction(c.HandlersChain[len(c.HandlersChain)-1])
+}
+
+// HandlerNames returns the names of all the handlers of the matched route in
+// the order they are called, following the semantics of HandlerName().
+func (c *Context) HandlerNames() []string {
+	names := make([]string, 0, len(c.HandlersChain))
+	for _, handler := range c.HandlersChain {
+		names = append(names, nameOfFunction(handler))
+	}
+	return names
+}
+
 // Next() used in middleware
 func (c *Context) Next() {
 	c.index++
-	for c.index < int8(len(c.HandlersChain)) {
+	for c.index < len(c.HandlersChain) {
 		c.HandlersChain[c.index](c)
 		c.index++
 	}
 }
 
+// Abort prevents pending handlers from being called. Note that this will not
+// stop the current handler. Let's say you have an authorization middleware
+// that validates that the current request is authorized. If the authorization
+// fails (ex: the password does not match), call Abort to ensure the remaining
+// handlers for this request are not called.
 func (c *Context) Abort() {
 	c.index = abortInx
 }
 
+// IsAborted returns true if the current context was aborted.
+func (c *Context) IsAborted() bool {
+	return c.index >= abortInx
+}
+
+// AbortWithStatus calls Abort() and writes the headers with the specified
+// status code. For example, a failed attempt to authenticate a request could
+// use: context.AbortWithStatus(401).
+func (c *Context) AbortWithStatus(code int) {
+	c.Status(code)
+	c.Writer.WriteHeaderNow()
+	c.Abort()
+}
+
+// AbortWithStatusJSON calls Abort() and then JSON internally.
+// This method stops the chain, writes the status code and returns a JSON body.
+func (c *Context) AbortWithStatusJSON(code int, jsonObj interface{}) {
+	c.Abort()
+	c.JSON(code, jsonObj)
+}
+
+// AbortWithError calls AbortWithStatus() and Error() internally.
+// This method stops the chain, writes the status code and pushes the
+// specified error to c.Errors. See Context.Error() for more details.
+func (c *Context) AbortWithError(code int, err error) *Error {
+	c.AbortWithStatus(code)
+	return c.Error(err)
+}
+
+// Error attaches an error to the current context. The error is pushed to a list of errors.
+// It's a good idea to call Error for each error that occurred during the resolution of a request.
+// A middleware can be used to collect all the errors and push them to a database together,
+// print a log, or append it in the HTTP response.
+// Error will panic if err is nil.
+func (c *Context) Error(err error) *Error {
+	if err == nil {
+		panic("err is nil")
+	}
+
+	parsedError, ok := err.(*Error)
+	if !ok {
+		parsedError = &Error{
+			Err:  err,
+			Type: ErrorTypePrivate,
+		}
+	}
+
+	c.Errors = append(c.Errors, parsedError)
+	return parsedError
+}
+
 // Set is used to store a new key/value pair exclusively for this context.
 // It also lazy initializes  c.Keys if it was not used previously.
 func (c *Context) Set(key string, value interface{}) {
@@ -143,6 +263,7 @@ func (c *Context) JSON(code int, obj interface{}) {
 	c.Status(code)
 	encoder := json.NewEncoder(c.Writer)
 	if err := encoder.Encode(obj); err != nil {
+		c.Error(err).SetType(ErrorTypeRender)
 		http.Error(c.Writer, err.Error(), http.StatusInternalServerError)
 	}
 }
@@ -164,6 +285,200 @@ func (c *Context) String(code int, format string, str ...interface{}) {
 	c.Writer.Write([]byte(fmt.Sprintf(format, str...)))
 }
 
+// Redirect returns an HTTP redirect to the specific location. The code must be
+// a 3xx status, or 201 Created which only sets the Location header. Relative
+// locations are resolved against the request URL.
+func (c *Context) Redirect(code int, location string) {
+	if (code < http.StatusMultipleChoices || code > http.StatusPermanentRedirect) && code != http.StatusCreated {
+		panic(fmt.Sprintf("Cannot redirect with status code %d", code))
+	}
+	if u, err := url.Parse(location); err == nil && c.Request != nil {
+		location = c.Request.URL.ResolveReference(u).String()
+	}
+
+	if code == http.StatusCreated {
+		c.SetHeader("Location", location)
+		c.Status(code)
+		return
+	}
+	http.Redirect(c.Writer, c.Request, location, code)
+}
+
+// SSEvent writes a Server-Sent Event into the body stream. An event that
+// can not be encoded is added to c.Errors, see WriteEvent.
+func (c *Context) SSEvent(name string, message interface{}) {
+	if err := c.WriteEvent(Event{Event: name, Data: message}); err != nil {
+		c.Error(err).SetType(ErrorTypeRender)
+	}
+}
+
+// WriteEvent writes the given Server-Sent Event into the body stream and flushes it
+// to the client, setting the event-stream headers on the first call.
+func (c *Context) WriteEvent(e Event) error {
+	header := c.Writer.Header()
+	if header.Get("Content-Type") != "text/event-stream" {
+		header.Set("Content-Type", "text/event-stream")
+		header.Set("Cache-Control", "no-cache")
+		header.Set("X-Accel-Buffering", "no")
+	}
+	if err := e.Encode(c.Writer); err != nil {
+		return err
+	}
+	c.Writer.Flush()
+	return nil
+}
+
+// Stream sends a streaming response, flushing after each step, and returns true
+// if the client disconnected in the middle of the stream. The step function is
+// called until it returns false or the request context is done.
+func (c *Context) Stream(step func(w io.Writer) bool) bool {
+	w := c.Writer
+	clientGone := c.Request.Context().Done()
+	for {
+		select {
+		case <-clientGone:
+			return true
+		default:
+			keepOpen := step(w)
+			w.Flush()
+			if !keepOpen {
+				return false
+			}
+		}
+	}
+}
+
+// DataFromReader writes the specified reader into the body stream and updates the HTTP code.
+// The reader is streamed without buffering. When code is 200 and the request carries a
+// Range header, the requested byte range is served with 206 Partial Content, or with the
+// status chosen by http.ServeContent for a seekable reader, e.g. 416. In both cases the
+// content is the contentLength bytes following the current offset of the reader, or the
+// rest of a seekable reader when contentLength is negative.
+func (c *Context) DataFromReader(code int, contentLength int64, contentType string, reader io.Reader, extraHeaders map[string]string) {
+	header := c.Writer.Header()
+	for k, v := range extraHeaders {
+		header.Set(k, v)
+	}
+	if contentType != "" {
+		header.Set("Content-Type", contentType)
+	}
+
+	if code == http.StatusOK && c.Request != nil && c.requestHeader("Range") != "" {
+		// Seekable readers get the full net/http treatment, including multiple ranges.
+		if rs, ok := reader.(io.ReadSeeker); ok {
+			if contentLength >= 0 {
+				base, err := rs.Seek(0, io.SeekCurrent)
+				if err == nil {
+					rs = &sizedReadSeeker{rs: rs, base: base, size: contentLength}
+				}
+			}
+			modtime, _ := http.ParseTime(header.Get("Last-Modified"))
+			http.ServeContent(c.Writer, c.Request, "", modtime, rs)
+			c.StatusCode = c.Writer.Status()
+			return
+		}
+		if c.serveRange(contentLength, reader) {
+			return
+		}
+	}
+
+	if contentLength >= 0 {
+		header.Set("Accept-Ranges", "bytes")
+		header.Set("Content-Length", strconv.FormatInt(contentLength, 10))
+	}
+	c.Status(code)
+	io.Copy(c.Writer, reader)
+}
+
+// sizedReadSeeker exposes the size bytes of rs following base.
+type sizedReadSeeker struct {
+	rs         io.ReadSeeker
+	base, size int64
+	off        int64
+}
+
+func (r *sizedReadSeeker) Read(p []byte) (int, error) {
+	if r.off >= r.size {
+		return 0, io.EOF
+	}
+	if max := r.size - r.off; int64(len(p)) > max {
+		p = p[:max]
+	}
+	n, err := r.rs.Read(p)
+	r.off += int64(n)
+	return n, err
+}
+
+func (r *sizedReadSeeker) Seek(offset int64, whence int) (int64, error) {
+	switch whence {
+	case io.SeekCurrent:
+		offset += r.off
+	case io.SeekEnd:
+		offset += r.size
+	}
+	if offset < 0 {
+		return 0, errors.New("rum: seek before the start of the content")
+	}
+	if _, err := r.rs.Seek(r.base+offset, io.SeekStart); err != nil {
+		return 0, err
+	}
+	r.off = offset
+	return offset, nil
+}
+
+// serveRange serves a single byte range from a non-seekable reader of the given size.
+// It returns false when the Range header should be ignored and the whole body sent.
+func (c *Context) serveRange(size int64, reader io.Reader) bool {
+	if size < 0 || !ifRangeMatches(c.requestHeader("If-Range"), c.Writer.Header()) {
+		return false
+	}
+	ra, err := parseRange(c.requestHeader("Range"), size)
+	if err != nil {
+		c.SetHeader("Content-Range", fmt.Sprintf("bytes */%d", size))
+		c.Status(http.StatusRequestedRangeNotSatisfiable)
+		return true
+	}
+	if ra == nil {
+		return false
+	}
+
+	if _, err := io.CopyN(ioutil.Discard, reader, ra.start); err != nil {
+		c.Status(http.StatusInternalServerError)
+		return true
+	}
+	c.SetHeader("Accept-Ranges", "bytes")
+	c.SetHeader("Content-Range", ra.contentRange(size))
+	c.SetHeader("Content-Length", strconv.FormatInt(ra.length, 10))
+	c.Status(http.StatusPartialContent)
+	if c.Request.Method != http.MethodHead {
+		io.CopyN(c.Writer, reader, ra.length)
+	}
+	return true
+}
+
+// File writes the specified file into the body stream in an efficient way.
+func (c *Context) File(filepath string) {
+	http.ServeFile(c.Writer, c.Request, filepath)
+}
+
+// FileFromFS writes the specified file from http.FileSystem into the body stream in an efficient way.
+func (c *Context) FileFromFS(filepath string, fs http.FileSystem) {
+	defer func(old string) {
+		c.Request.URL.Path = old
+	}(c.Request.URL.Path)
+
+	c.Request.URL.Path = filepath
+
+	http.FileServer(fs).ServeHTTP(c.Writer, c.Request)
+}
+
+// FileAttachment writes the specified file into the body stream in an efficient way
+// On the client side, the file will typically be downloaded with the given filename
+func (c *Context) FileAttachment(filepath, filename string) {
+	c.SetHeader("Content-Disposition", contentDisposition("attachment", filename))
+	http.ServeFile(c.Writer, c.Request, filepath)
+}
+
 func (c *Context) ContentType() string {
 	return filterFlags(c.requestHeader("Content-Type"))
 }
@@ -172,33 +487,180 @@ func (c *Context) requestHeader(key string) string {
 	return c.Request.Header.Get(key)
 }
 
+// Bind checks the Method and Content-Type to select a binding engine automatically,
+// Depending on the "Content-Type" header different bindings are used, for example:
+//
+//	"application/json" --> JSON binding
+//	"application/xml"  --> XML binding
+//
+// It decodes the request body into obj. If the binding fails the request is
+// aborted with 400 and the error is recorded in c.Errors. See ShouldBind for
+// a variant that only returns the error.
 func (c *Context) Bind(obj interface{}) error {
 	b := binding.Default(c.Request.Method, c.ContentType())
-	return c.ShouldBindWith(obj, b)
+	return c.MustBindWith(obj, b)
 }
 
+// BindJSON is a shortcut for c.MustBindWith(obj, binding.JSON).
 func (c *Context) BindJSON(obj interface{}) error {
-	return c.ShouldBindWith(obj, binding.JSON)
+	return c.MustBindWith(obj, binding.JSON)
+}
+
+// BindXML is a shortcut for c.MustBindWith(obj, binding.XML).
+func (c *Context) BindXML(obj interface{}) error {
+	return c.MustBindWith(obj, binding.XML)
+}
+
+// BindYAML is a shortcut for c.MustBindWith(obj, binding.YAML).
+func (c *Context) BindYAML(obj interface{}) error {
+	return c.MustBindWith(obj, binding.YAML)
+}
+
+// BindTOML is a shortcut for c.MustBindWith(obj, binding.TOML).
+func (c *Context) BindTOML(obj interface{}) error {
+	return c.MustBindWith(obj, binding.TOML)
+}
+
+// BindMsgPack is a shortcut for c.MustBindWith(obj, binding.MsgPack).
+func (c *Context) BindMsgPack(obj interface{}) error {
+	return c.MustBindWith(obj, binding.MsgPack)
+}
+
+// BindProtoBuf is a shortcut for c.MustBindWith(obj, binding.ProtoBuf).
+func (c *Context) BindProtoBuf(obj interface{}) error {
+	return c.MustBindWith(obj, binding.ProtoBuf)
 }
 
+// BindHeader is a shortcut for c.MustBindWith(obj, binding.Header).
 func (c *Context) BindHeader(obj interface{}) error {
-	return c.ShouldBindWith(obj, binding.Header)
+	return c.MustBindWith(obj, binding.Header)
 }
 
+// BindQuery is a shortcut for c.MustBindWith(obj, binding.Query).
 func (c *Context) BindQuery(obj interface{}) error {
+	return c.MustBindWith(obj, binding.Query)
+}
+
+// BindUri binds the passed struct pointer using binding.Uri.
+// It will abort the request with HTTP 400 if any error occurs.
+func (c *Context) BindUri(obj interface{}) error {
+	if err := c.ShouldBindUri(obj); err != nil {
+		c.abortWithBindError(err)
+		return err
+	}
+	return nil
+}
+
+// MustBindWith binds the passed struct pointer using the specified binding engine.
+// It will abort the request with HTTP 400 if any error occurs.
+func (c *Context) MustBindWith(obj interface{}, b binding.Binding) error {
+	if err := c.ShouldBindWith(obj, b); err != nil {
+		c.abortWithBindError(err)
+		return err
+	}
+	return nil
+}
+
+func (c *Context) abortWithBindError(err error) {
+	code := http.StatusBadRequest
+	if c.bodyTooLarge() || errors.Is(err, ErrBodyTooLarge) {
+		code = http.StatusRequestEntityTooLarge
+	}
+	c.AbortWithError(code, err).SetType(ErrorTypeBind)
+}
+
+// ShouldBind checks the Method and Content-Type to select a binding engine automatically,
+// like Bind, but it only returns the error and leaves the response untouched.
+func (c *Context) ShouldBind(obj interface{}) error {
+	b := binding.Default(c.Request.Method, c.ContentType())
+	return c.ShouldBindWith(obj, b)
+}
+
+// ShouldBindJSON is a shortcut for c.ShouldBindWith(obj, binding.JSON).
+func (c *Context) ShouldBindJSON(obj interface{}) error {
+	return c.ShouldBindWith(obj, binding.JSON)
+}
+
+// ShouldBindXML is a shortcut for c.ShouldBindWith(obj, binding.XML).
+func (c *Context) ShouldBindXML(obj interface{}) error {
+	return c.ShouldBindWith(obj, binding.XML)
+}
+
+// ShouldBindYAML is a shortcut for c.ShouldBindWith(obj, binding.YAML).
+func (c *Context) ShouldBindYAML(obj interface{}) error {
+	return c.ShouldBindWith(obj, binding.YAML)
+}
+
+// ShouldBindTOML is a shortcut for c.ShouldBindWith(obj, binding.TOML).
+func (c *Context) ShouldBindTOML(obj interface{}) error {
+	return c.ShouldBindWith(obj, binding.TOML)
+}
+
+// ShouldBindMsgPack is a shortcut for c.ShouldBindWith(obj, binding.MsgPack).
+func (c *Context) ShouldBindMsgPack(obj interface{}) error {
+	return c.ShouldBindWith(obj, binding.MsgPack)
+}
+
+// ShouldBindProtoBuf is a shortcut for c.ShouldBindWith(obj, binding.ProtoBuf).
+func (c *Context) ShouldBindProtoBuf(obj interface{}) error {
+	return c.ShouldBindWith(obj, binding.ProtoBuf)
+}
+
+// ShouldBindHeader is a shortcut for c.ShouldBindWith(obj, binding.Header).
+func (c *Context) ShouldBindHeader(obj interface{}) error {
+	return c.ShouldBindWith(obj, binding.Header)
+}
+
+// ShouldBindQuery is a shortcut for c.ShouldBindWith(obj, binding.Query).
+func (c *Context) ShouldBindQuery(obj interface{}) error {
 	return c.ShouldBindWith(obj, binding.Query)
 }
 
+// ShouldBindUri binds the passed struct pointer using the route params.
 func (c *Context) ShouldBindUri(obj interface{}) error {
-	m := make(map[string][]string)
+	return binding.BindUriContext(c.bindingContext(), binding.Uri, c.paramsMap(), obj)
+}
+
+// ShouldBindAll fills the passed struct pointer from the body, form, query,
+// header and route params in a single call and validates it once.
+// See binding.BindAll for the precedence of the sources.
+func (c *Context) ShouldBindAll(obj interface{}) error {
+	c.bindingContext()
+	if err := c.parseMultipartBody(); err != nil {
+		return err
+	}
+	return binding.BindAll(c.Request, c.paramsMap(), obj)
+}
+
+// BindAll is like ShouldBindAll, but it aborts the request with HTTP 400 if any error occurs.
+func (c *Context) BindAll(obj interface{}) error {
+	if err := c.ShouldBindAll(obj); err != nil {
+		c.abortWithBindError(err)
+		return err
+	}
+	return nil
+}
+
+func (c *Context) paramsMap() map[string][]string {
+	m := make(map[string][]string, len(c.Params))
 	for _, v := range c.Params {
 		m[v.Key] = []string{v.Value}
 	}
-	return binding.Uri.BindUri(m, obj)
+	return m
+}
+
+// TranslateErrors renders the messages of the binding errors found in err in
+// the language requested by the Accept-Language header. The messages refer to
+// the fields by the key of their source, e.g. the json or form name.
+func (c *Context) TranslateErrors(err error) binding.Errors {
+	return binding.Translate(err, c.requestHeader("Accept-Language"))
 }
 
 // ShouldBindBodyWith is similar with ShouldBindWith, but it stores the request
-// body into the context, and reuse when it is called again.
+// body into the context, and reuse when it is called again. As the body is
+// read in memory, it is limited to DefaultBodyWithMaxBytes unless
+// Engine.MaxBodyBytes or MaxBodyBytes set a limit; reading a larger body fails
+// with ErrBodyTooLarge.
 func (c *Context) ShouldBindBodyWith(obj interface{}, bb binding.BindingBody) (err error) {
 	var body []byte
 	if cb, ok := c.Get(BodyKey); ok {
@@ -207,23 +669,108 @@ func (c *Context) ShouldBindBodyWith(obj interface{}, bb binding.BindingBody) (e
 		}
 	}
 	if body == nil {
+		if c.body == nil {
+			c.limitBody(DefaultBodyWithMaxBytes)
+		}
 		body, err = ioutil.ReadAll(c.Request.Body)
 		if err != nil {
 			return err
 		}
 		c.Set(BodyKey, body)
 	}
-	return bb.BindBody(body, obj)
+	return binding.BindBodyContext(c.bindingContext(), bb, body, obj)
 }
 
+// ShouldBindWith binds the passed struct pointer using the specified binding engine.
 func (c *Context) ShouldBindWith(obj interface{}, b binding.Binding) error {
+	c.bindingContext()
+	if b == binding.Form || b == binding.FormMultipart {
+		if err := c.parseMultipartBody(); err != nil {
+			return err
+		}
+	}
 	return b.Bind(c.Request, obj)
 }
 
+// parseMultipartBody parses a multipart body with Engine.MaxMultipartMemory,
+// so that the binding finds it parsed.
+func (c *Context) parseMultipartBody() error {
+	if c.Request.MultipartForm != nil || c.ContentType() != MIMEMultipartPOSTForm {
+		return nil
+	}
+	return c.Request.ParseMultipartForm(c.maxMultipartMemory())
+}
+
+func (c *Context) maxMultipartMemory() int64 {
+	if c.engine == nil {
+		return defaultMultipartMemory
+	}
+	return c.engine.MaxMultipartMemory
+}
+
+// contextKey is the key of the contextRef in the context of its request.
+type contextKey struct{}
+
+// contextRef refers to the *Context of a request until it is handled. The
+// context of the request may be kept by a goroutine after the *Context went
+// back to the pool, so it does not carry the *Context itself.
+type contextRef struct {
+	mu sync.RWMutex
+	c  *Context
+}
+
+// bindingContext makes the context of c.Request carry c, so that validations
+// registered with binding.RegisterValidationCtx can retrieve it with
+// ContextFrom, and returns it. c.Request is replaced only once per request.
+func (c *Context) bindingContext() context.Context {
+	if c.Request == nil {
+		return context.Background()
+	}
+	ctx := c.Request.Context()
+	if c.ref != nil && ctx.Value(contextKey{}) == c.ref {
+		return ctx
+	}
+	if c.ref == nil {
+		c.ref = &contextRef{c: c}
+	}
+	ctx = context.WithValue(ctx, contextKey{}, c.ref)
+	c.Request = c.Request.WithContext(ctx)
+	return ctx
+}
+
+// release detaches c from the context of its request once it is handled.
+func (c *Context) release() {
+	if c.ref == nil {
+		return
+	}
+	c.ref.mu.Lock()
+	c.ref.c = nil
+	c.ref.mu.Unlock()
+	c.ref = nil
+}
+
+// ContextFrom returns the *Context carried by ctx, the context a validation
+// registered with binding.RegisterValidationCtx or
+// binding.RegisterStructValidationCtx receives while binding a request.
+//
+// The *Context is only valid until its request is handled, as it is then
+// reused for another request: it must not be kept, and ContextFrom returns
+// false once the request is handled.
+func ContextFrom(ctx context.Context) (*Context, bool) {
+	ref, ok := ctx.Value(contextKey{}).(*contextRef)
+	if !ok {
+		return nil, false
+	}
+	ref.mu.RLock()
+	c := ref.c
+	ref.mu.RUnlock()
+	return c, c != nil
+}
+
 // FormFile returns the first file for the provided form key.
 func (c *Context) FormFile(name string) (*multipart.FileHeader, error) {
 	if c.Request.MultipartForm == nil {
-		if err := c.Request.ParseMultipartForm(defaultMultipartMemory); err != nil {
+		if err := c.Request.ParseMultipartForm(c.maxMultipartMemory()); err != nil {
 			return nil, err
 		}
 	}
@@ -237,24 +784,12 @@ func (c *Context) FormFile(name string) (*multipart.FileHeader, error) {
 
 // MultipartForm is the parsed multipart form, including file uploads.
 func (c *Context) MultipartForm() (*multipart.Form, error) {
-	err := c.Request.ParseMultipartForm(defaultMultipartMemory)
+	err := c.Request.ParseMultipartForm(c.maxMultipartMemory())
 	return c.Request.MultipartForm, err
 }
 
 // SaveUploadedFile uploads the form file to specific dst.
+// See SaveUploadedFileWith to save it safely.
 func (c *Context) SaveUploadedFile(file *multipart.FileHeader, dst string) error {
-	src, err := file.Open()
-	if err != nil {
-		return err
-	}
-	defer src.Close()
-
-	out, err := os.Create(dst)
-	if err != nil {
-		return err
-	}
-	defer out.Close()
-
-	_, err = io.Copy(out, src)
-	return err
+	return c.SaveUploadedFileWith(file, dst, SaveOptions{})
 }
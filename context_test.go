@@ -6,13 +6,19 @@ package rum
 
 import (
 	"bytes"
+	"context"
+	"errors"
+	"io"
+	"io/ioutil"
 	"mime/multipart"
 	"net/http"
 	"net/http/httptest"
 	"os"
+	"strings"
 	"testing"
 
 	"github.com/MichaelDeSteven/rum/binding"
+	"github.com/go-playground/validator/v10"
 	"github.com/stretchr/testify/assert"
 )
 
@@ -46,6 +52,61 @@ func TestContextAutoBindJSON(t *testing.T) {
 	assert.Empty(t, c.Errors)
 }
 
+func TestContextBindWithXML(t *testing.T) {
+	w := httptest.NewRecorder()
+	c, _ := CreateTestContext(w)
+
+	c.Request, _ = http.NewRequest("POST", "/", bytes.NewBufferString(`<?xml version="1.0" encoding="UTF-8"?>
+		<root>
+			<foo>FOO</foo>
+		   	<bar>BAR</bar>
+		</root>`))
+	c.Request.Header.Add("Content-Type", MIMEXML)
+
+	var obj struct {
+		Foo string `xml:"foo"`
+		Bar string `xml:"bar"`
+	}
+	assert.NoError(t, c.BindXML(&obj))
+	assert.Equal(t, "FOO", obj.Foo)
+	assert.Equal(t, "BAR", obj.Bar)
+	assert.Equal(t, 0, w.Body.Len())
+}
+
+func TestContextBindWithYAML(t *testing.T) {
+	w := httptest.NewRecorder()
+	c, _ := CreateTestContext(w)
+
+	c.Request, _ = http.NewRequest("POST", "/", bytes.NewBufferString("foo: bar\nbar: foo"))
+	c.Request.Header.Add("Content-Type", MIMEXML) // set fake content-type
+
+	var obj struct {
+		Foo string `yaml:"foo"`
+		Bar string `yaml:"bar"`
+	}
+	assert.NoError(t, c.BindYAML(&obj))
+	assert.Equal(t, "foo", obj.Bar)
+	assert.Equal(t, "bar", obj.Foo)
+	assert.Equal(t, 0, w.Body.Len())
+}
+
+func TestContextBindWithTOML(t *testing.T) {
+	w := httptest.NewRecorder()
+	c, _ := CreateTestContext(w)
+
+	c.Request, _ = http.NewRequest("POST", "/", bytes.NewBufferString("foo = 'bar'\nbar = 'foo'"))
+	c.Request.Header.Add("Content-Type", MIMEXML) // set fake content-type
+
+	var obj struct {
+		Foo string `toml:"foo"`
+		Bar string `toml:"bar"`
+	}
+	assert.NoError(t, c.BindTOML(&obj))
+	assert.Equal(t, "foo", obj.Bar)
+	assert.Equal(t, "bar", obj.Foo)
+	assert.Equal(t, 0, w.Body.Len())
+}
+
 func TestContextBindHeader(t *testing.T) {
 	w := httptest.NewRecorder()
 	c, _ := CreateTestContext(w)
@@ -62,6 +123,7 @@ func TestContextBindHeader(t *testing.T) {
 	}
 
 	assert.NoError(t, c.BindHeader(&testHeader))
+	assert.NoError(t, c.ShouldBindHeader(&testHeader))
 	assert.Equal(t, 8000, testHeader.Rate)
 	assert.Equal(t, "music", testHeader.Domain)
 	assert.Equal(t, 1000, testHeader.Limit)
@@ -103,6 +165,23 @@ func TestContextShouldBindBodyWith(t *testing.T) {
 			bodyA:    `{"foo":"FOO"}`,
 			bodyB:    `{"bar":"BAR"}`,
 		},
+		{
+			name:     "JSON & XML",
+			bindingA: binding.JSON,
+			bindingB: binding.XML,
+			bodyA:    `{"foo":"FOO"}`,
+			bodyB: `<?xml version="1.0" encoding="UTF-8"?>
+<root>
+   <bar>BAR</bar>
+</root>`,
+		},
+		{
+			name:     "XML & XML",
+			bindingA: binding.XML,
+			bindingB: binding.XML,
+			bodyA:    "<?xml version=\"1.0\" encoding=\"UTF-8\"?>\n<root>\n   <foo>FOO</foo>\n</root>",
+			bodyB:    "<?xml version=\"1.0\" encoding=\"UTF-8\"?>\n<root>\n   <bar>BAR</bar>\n</root>",
+		},
 	} {
 		t.Logf("testing: %s", tt.name)
 		// bodyA to typeA and typeB
@@ -140,6 +219,40 @@ func TestContextShouldBindBodyWith(t *testing.T) {
 	}
 }
 
+// csvBinding is a custom binding which is not comparable.
+type csvBinding struct {
+	fields []string
+}
+
+func (b csvBinding) Name() string { return "csv" }
+
+func (b csvBinding) Bind(req *http.Request, obj interface{}) error {
+	body, err := ioutil.ReadAll(req.Body)
+	if err != nil {
+		return err
+	}
+	return b.BindBody(body, obj)
+}
+
+func (b csvBinding) BindBody(body []byte, obj interface{}) error {
+	m := obj.(map[string]string)
+	for i, v := range strings.Split(string(body), ",") {
+		m[b.fields[i]] = v
+	}
+	return nil
+}
+
+func TestContextShouldBindBodyWithCustomBinding(t *testing.T) {
+	c, _ := CreateTestContext(httptest.NewRecorder())
+	c.Request, _ = http.NewRequest("POST", "/", bytes.NewBufferString("rum,42"))
+
+	obj := make(map[string]string)
+	assert.NotPanics(t, func() {
+		assert.NoError(t, c.ShouldBindBodyWith(obj, csvBinding{fields: []string{"name", "age"}}))
+	})
+	assert.Equal(t, map[string]string{"name": "rum", "age": "42"}, obj)
+}
+
 func TestContextBadAutoBind(t *testing.T) {
 	w := httptest.NewRecorder()
 	c, _ := CreateTestContext(w)
@@ -152,9 +265,68 @@ func TestContextBadAutoBind(t *testing.T) {
 	}
 
 	assert.Error(t, c.Bind(&obj))
+	c.Writer.WriteHeaderNow()
 
 	assert.Empty(t, obj.Bar)
 	assert.Empty(t, obj.Foo)
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+	assert.Equal(t, abortInx, c.index)
+	if assert.Len(t, c.Errors, 1) {
+		assert.True(t, c.Errors.Last().IsType(ErrorTypeBind))
+	}
+}
+
+func TestContextBadShouldBind(t *testing.T) {
+	w := httptest.NewRecorder()
+	c, _ := CreateTestContext(w)
+
+	c.Request, _ = http.NewRequest("POST", "http://example.com", bytes.NewBufferString("\"foo\":\"bar\", \"bar\":\"foo\"}"))
+	c.Request.Header.Add("Content-Type", MIMEJSON)
+	var obj struct {
+		Foo string `json:"foo"`
+		Bar string `json:"bar"`
+	}
+
+	assert.Error(t, c.ShouldBind(&obj))
+	assert.Error(t, c.ShouldBindJSON(&obj))
+
+	assert.Empty(t, obj.Foo)
+	assert.Equal(t, -1, c.index)
+	assert.Empty(t, c.Errors)
+	assert.False(t, c.Writer.Written())
+}
+
+func TestContextBadBindQuery(t *testing.T) {
+	w := httptest.NewRecorder()
+	c, _ := CreateTestContext(w)
+
+	c.Request, _ = http.NewRequest("GET", "/?age=ten", nil)
+	var obj struct {
+		Age int `form:"age"`
+	}
+
+	assert.Error(t, c.ShouldBindQuery(&obj))
+	assert.Empty(t, c.Errors)
+
+	assert.Error(t, c.BindQuery(&obj))
+	assert.Equal(t, abortInx, c.index)
+	assert.Len(t, c.Errors.ByType(ErrorTypeBind), 1)
+}
+
+func TestContextBindUri(t *testing.T) {
+	c, _ := CreateTestContext(httptest.NewRecorder())
+	c.Params = Params{{Key: "id", Value: "42"}}
+
+	var obj struct {
+		ID int `uri:"id" binding:"required"`
+	}
+	assert.NoError(t, c.BindUri(&obj))
+	assert.Equal(t, 42, obj.ID)
+
+	c.Params = Params{{Key: "id", Value: "abc"}}
+	assert.Error(t, c.BindUri(&obj))
+	assert.Equal(t, abortInx, c.index)
+	assert.Len(t, c.Errors, 1)
 }
 
 func TestContextFormFile(t *testing.T) {
@@ -253,7 +425,7 @@ func TestPath404NotFound(t *testing.T) {
 }
 
 func TestGetParams(t *testing.T) {
-	router := Default()
+	router := New(":9678")
 	router.Use(func(c *Context) {
 		// TEST
 		assert.Equal(t, "pp", c.Param("param"))
@@ -266,3 +438,635 @@ func TestGetParams(t *testing.T) {
 	// TEST
 	assert.Equal(t, http.StatusOK, w.Code)
 }
+
+func TestContextRenderFile(t *testing.T) {
+	w := httptest.NewRecorder()
+	c, _ := CreateTestContext(w)
+
+	c.Request, _ = http.NewRequest("GET", "/", nil)
+	c.File("./engine.go")
+
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Contains(t, w.Body.String(), "func New(addr string) *Engine {")
+}
+
+func TestContextRenderFileFromFS(t *testing.T) {
+	w := httptest.NewRecorder()
+	c, _ := CreateTestContext(w)
+
+	c.Request, _ = http.NewRequest("GET", "/some/path", nil)
+	c.FileFromFS("./engine.go", http.Dir("."))
+
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Contains(t, w.Body.String(), "func New(addr string) *Engine {")
+	assert.Equal(t, "/some/path", c.Request.URL.Path)
+}
+
+func TestContextRenderAttachment(t *testing.T) {
+	w := httptest.NewRecorder()
+	c, _ := CreateTestContext(w)
+
+	c.Request, _ = http.NewRequest("GET", "/", nil)
+	c.FileAttachment("./engine.go", "report.go")
+
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Contains(t, w.Body.String(), "func New(addr string) *Engine {")
+	assert.Equal(t, `attachment; filename="report.go"`, w.Header().Get("Content-Disposition"))
+}
+
+func TestContextRenderUTF8Attachment(t *testing.T) {
+	w := httptest.NewRecorder()
+	c, _ := CreateTestContext(w)
+
+	c.Request, _ = http.NewRequest("GET", "/", nil)
+	c.FileAttachment("./engine.go", "报表 2022.go")
+
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Equal(t, `attachment; filename="__ 2022.go"; filename*=UTF-8''%E6%8A%A5%E8%A1%A8%202022.go`,
+		w.Header().Get("Content-Disposition"))
+}
+
+func TestContextDataFromReader(t *testing.T) {
+	w := httptest.NewRecorder()
+	c, _ := CreateTestContext(w)
+	c.Request, _ = http.NewRequest("GET", "/", nil)
+
+	body := "#!PNG some raw data"
+	reader := strings.NewReader(body)
+	extraHeaders := map[string]string{"Content-Disposition": `attachment; filename="gopher.png"`}
+
+	c.DataFromReader(http.StatusOK, int64(len(body)), "image/png", reader, extraHeaders)
+
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Equal(t, body, w.Body.String())
+	assert.Equal(t, "image/png", w.Header().Get("Content-Type"))
+	assert.Equal(t, "19", w.Header().Get("Content-Length"))
+	assert.Equal(t, `attachment; filename="gopher.png"`, w.Header().Get("Content-Disposition"))
+}
+
+func TestContextDataFromReaderRange(t *testing.T) {
+	body := "0123456789abcdefghij"
+	for _, tt := range []struct {
+		name   string
+		reader func() io.Reader
+	}{
+		{"seekable", func() io.Reader { return strings.NewReader(body) }},
+		{"stream", func() io.Reader { return ioutil.NopCloser(strings.NewReader(body)) }},
+	} {
+		t.Run(tt.name, func(t *testing.T) {
+			w := httptest.NewRecorder()
+			c, _ := CreateTestContext(w)
+			c.Request, _ = http.NewRequest("GET", "/", nil)
+			c.Request.Header.Set("Range", "bytes=5-9")
+			c.DataFromReader(http.StatusOK, int64(len(body)), "text/plain", tt.reader(), nil)
+
+			assert.Equal(t, http.StatusPartialContent, w.Code)
+			assert.Equal(t, "56789", w.Body.String())
+			assert.Equal(t, "bytes 5-9/20", w.Header().Get("Content-Range"))
+			assert.Equal(t, "5", w.Header().Get("Content-Length"))
+
+			w = httptest.NewRecorder()
+			c, _ = CreateTestContext(w)
+			c.Request, _ = http.NewRequest("GET", "/", nil)
+			c.Request.Header.Set("Range", "bytes=-3")
+			c.DataFromReader(http.StatusOK, int64(len(body)), "text/plain", tt.reader(), nil)
+
+			assert.Equal(t, http.StatusPartialContent, w.Code)
+			assert.Equal(t, "hij", w.Body.String())
+
+			w = httptest.NewRecorder()
+			c, _ = CreateTestContext(w)
+			c.Request, _ = http.NewRequest("GET", "/", nil)
+			c.Request.Header.Set("Range", "bytes=30-")
+			c.DataFromReader(http.StatusOK, int64(len(body)), "text/plain", tt.reader(), nil)
+			c.Writer.WriteHeaderNow()
+
+			assert.Equal(t, http.StatusRequestedRangeNotSatisfiable, w.Code)
+			assert.Equal(t, "bytes */20", w.Header().Get("Content-Range"))
+		})
+	}
+}
+
+func TestContextDataFromReaderSeekableLength(t *testing.T) {
+	// the content is the 10 bytes following the current offset
+	reader := strings.NewReader("__0123456789abcdef")
+	reader.Seek(2, io.SeekStart)
+
+	w := httptest.NewRecorder()
+	c, _ := CreateTestContext(w)
+	c.Request, _ = http.NewRequest("GET", "/", nil)
+	c.Request.Header.Set("Range", "bytes=-3")
+	c.DataFromReader(http.StatusOK, 10, "text/plain", reader, nil)
+
+	assert.Equal(t, http.StatusPartialContent, w.Code)
+	assert.Equal(t, http.StatusPartialContent, c.Writer.Status())
+	assert.Equal(t, http.StatusPartialContent, c.StatusCode)
+	assert.Equal(t, "789", w.Body.String())
+	assert.Equal(t, "bytes 7-9/10", w.Header().Get("Content-Range"))
+
+	w = httptest.NewRecorder()
+	c, _ = CreateTestContext(w)
+	c.Request, _ = http.NewRequest("GET", "/", nil)
+	c.Request.Header.Set("Range", "bytes=10-")
+	c.DataFromReader(http.StatusOK, 10, "text/plain", strings.NewReader("0123456789abcdef"), nil)
+
+	assert.Equal(t, http.StatusRequestedRangeNotSatisfiable, w.Code)
+	assert.Equal(t, http.StatusRequestedRangeNotSatisfiable, c.Writer.Status())
+	assert.Equal(t, http.StatusRequestedRangeNotSatisfiable, c.StatusCode)
+	assert.Equal(t, "bytes */10", w.Header().Get("Content-Range"))
+}
+
+func TestContextDataFromReaderIfRange(t *testing.T) {
+	body := "0123456789"
+	headers := map[string]string{"ETag": `"v2"`}
+
+	w := httptest.NewRecorder()
+	c, _ := CreateTestContext(w)
+	c.Request, _ = http.NewRequest("GET", "/", nil)
+	c.Request.Header.Set("Range", "bytes=0-1")
+	c.Request.Header.Set("If-Range", `"v1"`)
+	c.DataFromReader(http.StatusOK, int64(len(body)), "text/plain", ioutil.NopCloser(strings.NewReader(body)), headers)
+
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Equal(t, body, w.Body.String())
+
+	w = httptest.NewRecorder()
+	c, _ = CreateTestContext(w)
+	c.Request, _ = http.NewRequest("GET", "/", nil)
+	c.Request.Header.Set("Range", "bytes=0-1")
+	c.Request.Header.Set("If-Range", `"v2"`)
+	c.DataFromReader(http.StatusOK, int64(len(body)), "text/plain", ioutil.NopCloser(strings.NewReader(body)), headers)
+
+	assert.Equal(t, http.StatusPartialContent, w.Code)
+	assert.Equal(t, "01", w.Body.String())
+}
+
+func TestContextSSEvent(t *testing.T) {
+	w := httptest.NewRecorder()
+	c, _ := CreateTestContext(w)
+	c.Request, _ = http.NewRequest("GET", "/", nil)
+
+	c.SSEvent("float", 1.5)
+	c.SSEvent("message", "hi!\nhow are you?")
+	c.SSEvent("", map[string]string{"foo": "bar"})
+	assert.NoError(t, c.WriteEvent(Event{Id: "42", Retry: 3000, Data: "line1\r\nline2"}))
+
+	assert.Equal(t, "text/event-stream", w.Header().Get("Content-Type"))
+	assert.Equal(t, "no-cache", w.Header().Get("Cache-Control"))
+	assert.True(t, w.Flushed)
+	assert.Equal(t, "event: float\ndata: 1.5\n\n"+
+		"event: message\ndata: hi!\ndata: how are you?\n\n"+
+		"data: {\"foo\":\"bar\"}\n\n"+
+		"id: 42\nretry: 3000\ndata: line1\ndata: line2\n\n", w.Body.String())
+}
+
+func TestContextSSEventInvalidField(t *testing.T) {
+	w := httptest.NewRecorder()
+	c, _ := CreateTestContext(w)
+	c.Request, _ = http.NewRequest("GET", "/", nil)
+
+	for _, e := range []Event{
+		{Id: "1\nevent: forged", Data: "hi"},
+		{Id: "1\r2", Data: "hi"},
+		{Id: "1\x00", Data: "hi"},
+		{Event: "message\ndata: forged", Data: "hi"},
+	} {
+		assert.ErrorIs(t, c.WriteEvent(e), ErrInvalidEventField, "%q", e)
+	}
+	c.SSEvent("message\r\n", "hi")
+	assert.ErrorIs(t, c.Errors.Last(), ErrInvalidEventField)
+	assert.Empty(t, w.Body.String())
+
+	assert.NoError(t, c.WriteEvent(Event{Id: "a b\t", Data: "hi"}))
+	assert.Equal(t, "id: a b\t\ndata: hi\n\n", w.Body.String())
+}
+
+func TestContextStream(t *testing.T) {
+	w := httptest.NewRecorder()
+	c, _ := CreateTestContext(w)
+	c.Request, _ = http.NewRequest("GET", "/", nil)
+
+	stopStream := true
+	disconnected := c.Stream(func(w io.Writer) bool {
+		defer func() {
+			stopStream = false
+		}()
+
+		_, err := w.Write([]byte("test"))
+		assert.NoError(t, err)
+
+		return stopStream
+	})
+
+	assert.False(t, disconnected)
+	assert.Equal(t, "testtest", w.Body.String())
+	assert.True(t, w.Flushed)
+}
+
+func TestContextStreamWithClientGone(t *testing.T) {
+	w := httptest.NewRecorder()
+	c, _ := CreateTestContext(w)
+	ctx, cancel := context.WithCancel(context.Background())
+	c.Request, _ = http.NewRequestWithContext(ctx, "GET", "/", nil)
+
+	disconnected := c.Stream(func(writer io.Writer) bool {
+		defer cancel()
+
+		_, err := writer.Write([]byte("test"))
+		assert.NoError(t, err)
+
+		return true
+	})
+
+	assert.True(t, disconnected)
+	assert.Equal(t, "test", w.Body.String())
+}
+
+func TestContextRenderRedirectWithRelativePath(t *testing.T) {
+	w := httptest.NewRecorder()
+	c, _ := CreateTestContext(w)
+
+	c.Request, _ = http.NewRequest("POST", "http://example.com/users/42/edit", nil)
+	assert.Panics(t, func() { c.Redirect(299, "/new_path") })
+	assert.Panics(t, func() { c.Redirect(309, "/new_path") })
+
+	c.Redirect(http.StatusMovedPermanently, "../profile")
+	c.Writer.WriteHeaderNow()
+	assert.Equal(t, http.StatusMovedPermanently, w.Code)
+	assert.Equal(t, "http://example.com/users/profile", w.Header().Get("Location"))
+}
+
+func TestContextRenderRedirectAll(t *testing.T) {
+	c, _ := CreateTestContext(httptest.NewRecorder())
+	c.Request, _ = http.NewRequest("GET", "/", nil)
+	assert.Panics(t, func() { c.Redirect(http.StatusOK, "/resource") })
+	assert.Panics(t, func() { c.Redirect(http.StatusAccepted, "/resource") })
+	assert.NotPanics(t, func() { c.Redirect(http.StatusMultipleChoices, "/resource") })
+	assert.NotPanics(t, func() { c.Redirect(http.StatusPermanentRedirect, "/resource") })
+}
+
+func TestContextRenderRedirectAbsolute(t *testing.T) {
+	w := httptest.NewRecorder()
+	c, _ := CreateTestContext(w)
+
+	c.Request, _ = http.NewRequest("GET", "/", nil)
+	c.Redirect(http.StatusFound, "http://google.com")
+	c.Writer.WriteHeaderNow()
+
+	assert.Equal(t, http.StatusFound, w.Code)
+	assert.Equal(t, "http://google.com", w.Header().Get("Location"))
+}
+
+func TestContextRenderRedirectCreated(t *testing.T) {
+	w := httptest.NewRecorder()
+	c, _ := CreateTestContext(w)
+
+	c.Request, _ = http.NewRequest("POST", "/users", nil)
+	c.Redirect(http.StatusCreated, "users/42")
+	c.Writer.WriteHeaderNow()
+
+	assert.Equal(t, http.StatusCreated, w.Code)
+	assert.Equal(t, "/users/42", w.Header().Get("Location"))
+	assert.Empty(t, w.Body.String())
+}
+
+func TestEngineHandleContext(t *testing.T) {
+	router := New(":9678")
+	signature := ""
+	router.Use(func(c *Context) {
+		signature += "A"
+		c.Next()
+		signature += "B"
+	})
+	router.GET("/", func(c *Context) {
+		c.Request.URL.Path = "/user/42"
+		router.HandleContext(c)
+		signature += "C"
+	})
+	router.GET("/user/:id", func(c *Context) {
+		c.String(http.StatusOK, "user %s", c.Param("id"))
+	})
+
+	w := PerformRequest(router, "GET", "/")
+
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Equal(t, "user 42", w.Body.String())
+	assert.Equal(t, "AABCB", signature)
+}
+
+func TestContextShouldBindAll(t *testing.T) {
+	router := New(":9678")
+	type user struct {
+		ID    int    `uri:"id" binding:"required"`
+		Name  string `json:"name" binding:"required"`
+		Tag   string `form:"tag"`
+		Agent string `header:"X-Agent"`
+	}
+	var got user
+	router.POST("/users/:id", func(c *Context) {
+		assert.NoError(t, c.ShouldBindAll(&got))
+	})
+	router.POST("/strict/:id", func(c *Context) {
+		var obj user
+		assert.Error(t, c.BindAll(&obj))
+	})
+
+	req := httptest.NewRequest("POST", "/users/42?tag=admin", bytes.NewBufferString(`{"name": "rum"}`))
+	req.Header.Set("Content-Type", MIMEJSON)
+	req.Header.Set("X-Agent", "test")
+	router.ServeHTTP(httptest.NewRecorder(), req)
+	assert.Equal(t, user{ID: 42, Name: "rum", Tag: "admin", Agent: "test"}, got)
+
+	req = httptest.NewRequest("POST", "/strict/42", bytes.NewBufferString(`{}`))
+	req.Header.Set("Content-Type", MIMEJSON)
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+}
+
+func TestContextTranslateErrors(t *testing.T) {
+	w := httptest.NewRecorder()
+	c, _ := CreateTestContext(w)
+	c.Request, _ = http.NewRequest("POST", "/", bytes.NewBufferString(`{}`))
+	c.Request.Header.Set("Content-Type", MIMEJSON)
+	c.Request.Header.Set("Accept-Language", "zh-CN,zh;q=0.9,en;q=0.8")
+
+	var obj struct {
+		UserName string `json:"user_name" binding:"required"`
+	}
+	errs := c.TranslateErrors(c.ShouldBind(&obj))
+	if assert.Len(t, errs, 1) {
+		assert.Equal(t, "user_name为必填字段", errs[0].Message)
+	}
+}
+
+func TestContextValidationCtx(t *testing.T) {
+	assert.NoError(t, binding.RegisterValidationCtx("tenant_region", func(ctx context.Context, fl validator.FieldLevel) bool {
+		c, ok := ContextFrom(ctx)
+		if !ok {
+			return false
+		}
+		region, _ := c.Get("region")
+		return fl.Field().String() == region
+	}))
+	type order struct {
+		Region string `json:"region" uri:"region" binding:"tenant_region"`
+	}
+
+	router := New(":9678")
+	router.Use(func(c *Context) {
+		c.Set("region", "eu")
+		c.Next()
+	})
+	var errs []error
+	router.POST("/orders/:region", func(c *Context) {
+		var obj order
+		errs = []error{
+			c.ShouldBindBodyWith(&obj, binding.JSON),
+			c.ShouldBindUri(&obj),
+		}
+	})
+	router.POST("/json", func(c *Context) {
+		var obj order
+		errs = []error{c.ShouldBindJSON(&obj)}
+	})
+
+	req := httptest.NewRequest("POST", "/orders/eu", bytes.NewBufferString(`{"region": "eu"}`))
+	router.ServeHTTP(httptest.NewRecorder(), req)
+	assert.Equal(t, []error{nil, nil}, errs)
+
+	req = httptest.NewRequest("POST", "/orders/us", bytes.NewBufferString(`{"region": "us"}`))
+	router.ServeHTTP(httptest.NewRecorder(), req)
+	if assert.Len(t, errs, 2) {
+		assert.Error(t, errs[0])
+		assert.Error(t, errs[1])
+	}
+
+	req = httptest.NewRequest("POST", "/json", bytes.NewBufferString(`{"region": "eu"}`))
+	router.ServeHTTP(httptest.NewRecorder(), req)
+	assert.Equal(t, []error{nil}, errs)
+
+	req = httptest.NewRequest("POST", "/json", bytes.NewBufferString(`{"region": "us"}`))
+	router.ServeHTTP(httptest.NewRecorder(), req)
+	assert.Error(t, errs[0])
+
+	_, ok := ContextFrom(context.Background())
+	assert.False(t, ok)
+}
+
+func TestContextFromHandledRequest(t *testing.T) {
+	router := New(":9678")
+	var ctx context.Context
+	router.POST("/", func(c *Context) {
+		ctx = c.bindingContext()
+		cc, ok := ContextFrom(ctx)
+		assert.True(t, ok)
+		assert.Same(t, c, cc)
+	})
+	router.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest("POST", "/", nil))
+
+	// the *Context went back to the pool and may serve another request
+	c, ok := ContextFrom(ctx)
+	assert.False(t, ok)
+	assert.Nil(t, c)
+}
+
+func TestContextLongHandlersChain(t *testing.T) {
+	router := New(":9678")
+	calls := 0
+	for i := 0; i < 200; i++ {
+		router.Use(func(c *Context) { calls++ })
+	}
+	router.GET("/", func(c *Context) {
+		assert.False(t, c.IsAborted())
+		c.String(http.StatusOK, "done")
+	})
+
+	w := PerformRequest(router, "GET", "/")
+	assert.Equal(t, 200, calls)
+	assert.Equal(t, "done", w.Body.String())
+}
+
+func TestContextIsAborted(t *testing.T) {
+	c, _ := CreateTestContext(httptest.NewRecorder())
+	assert.False(t, c.IsAborted())
+
+	c.Abort()
+	assert.True(t, c.IsAborted())
+
+	c.Next()
+	assert.True(t, c.IsAborted())
+
+	c.index++
+	assert.True(t, c.IsAborted())
+}
+
+func TestContextAbortWithStatus(t *testing.T) {
+	w := httptest.NewRecorder()
+	c, _ := CreateTestContext(w)
+
+	c.index = 4
+	c.AbortWithStatus(http.StatusUnauthorized)
+
+	assert.Equal(t, abortInx, c.index)
+	assert.Equal(t, http.StatusUnauthorized, c.Writer.Status())
+	assert.Equal(t, http.StatusUnauthorized, w.Code)
+	assert.True(t, c.IsAborted())
+}
+
+func TestContextJSONError(t *testing.T) {
+	w := httptest.NewRecorder()
+	c, _ := CreateTestContext(w)
+
+	c.JSON(http.StatusOK, make(chan int))
+
+	assert.Len(t, c.Errors.ByType(ErrorTypeRender), 1)
+	assert.Empty(t, c.Errors.ByType(ErrorTypeBind))
+}
+
+func TestContextAbortWithStatusJSON(t *testing.T) {
+	w := httptest.NewRecorder()
+	c, _ := CreateTestContext(w)
+	c.index = 4
+
+	c.AbortWithStatusJSON(http.StatusUnsupportedMediaType, map[string]string{"foo": "fooValue"})
+
+	assert.True(t, c.IsAborted())
+	assert.Equal(t, http.StatusUnsupportedMediaType, w.Code)
+	assert.Equal(t, "application/json", w.Header().Get("Content-Type"))
+	assert.Equal(t, "{\"foo\":\"fooValue\"}\n", w.Body.String())
+}
+
+func TestContextAbortWithError(t *testing.T) {
+	w := httptest.NewRecorder()
+	c, _ := CreateTestContext(w)
+
+	c.AbortWithError(http.StatusUnauthorized, errors.New("bad input")).SetMeta("some input")
+
+	assert.Equal(t, http.StatusUnauthorized, w.Code)
+	assert.True(t, c.IsAborted())
+	if assert.Len(t, c.Errors, 1) {
+		assert.Equal(t, "bad input", c.Errors.Last().Error())
+		assert.Equal(t, "some input", c.Errors.Last().Meta)
+		assert.True(t, c.Errors.Last().IsType(ErrorTypePrivate))
+	}
+}
+
+func TestContextAbortInMiddleware(t *testing.T) {
+	router := New(":9678")
+	var signature string
+	router.Use(func(c *Context) {
+		signature += "A"
+		c.Next()
+		signature += "B"
+	})
+	router.Use(func(c *Context) {
+		signature += "C"
+		c.AbortWithStatus(http.StatusForbidden)
+		c.Next()
+		signature += "D"
+	})
+	router.GET("/", func(c *Context) {
+		signature += "E"
+	})
+
+	w := PerformRequest(router, "GET", "/")
+	assert.Equal(t, "ACDB", signature)
+	assert.Equal(t, http.StatusForbidden, w.Code)
+}
+
+func handlerNameTest(c *Context) {}
+
+func handlerNameTest2(c *Context) {}
+
+func TestContextFullPath(t *testing.T) {
+	router := New(":9678")
+	paths := []string{
+		"/",
+		"/users",
+		"/users/:id",
+		"/users/:id/posts",
+		"/users/:id/posts/:post",
+		"/user_groups",
+		"/static/*filepath",
+	}
+	var got string
+	for _, path := range paths {
+		router.GET(path, func(c *Context) {
+			got = c.FullPath()
+		})
+	}
+
+	for request, want := range map[string]string{
+		"/":                    "/",
+		"/users":               "/users",
+		"/users/42":            "/users/:id",
+		"/users/42/posts":      "/users/:id/posts",
+		"/users/42/posts/7":    "/users/:id/posts/:post",
+		"/user_groups":         "/user_groups",
+		"/static/css/main.css": "/static/*filepath",
+	} {
+		got = ""
+		PerformRequest(router, "GET", request)
+		assert.Equal(t, want, got, request)
+	}
+
+	var notFound string
+	router.Use(func(c *Context) {
+		notFound = c.FullPath()
+	})
+	PerformRequest(router, "GET", "/missing")
+	assert.Empty(t, notFound)
+}
+
+func TestContextFullPathHandleContext(t *testing.T) {
+	router := New(":9678")
+	var paths []string
+	router.GET("/old/:id", func(c *Context) {
+		c.Request.URL.Path = "/new/" + c.Param("id")
+		router.HandleContext(c)
+		paths = append(paths, c.FullPath())
+	})
+	router.GET("/new/:id", func(c *Context) {
+		paths = append(paths, c.FullPath())
+	})
+
+	PerformRequest(router, "GET", "/old/1")
+	assert.Equal(t, []string{"/new/:id", "/old/:id"}, paths)
+}
+
+func TestContextParamHandleContext(t *testing.T) {
+	router := New(":9678")
+	var id, path, method string
+	router.GET("/a/:id", func(c *Context) {
+		c.Request.URL.Path = "/b/7"
+		c.Request.Method = "POST"
+		router.HandleContext(c)
+		id, path, method = c.Param("id"), c.Path, c.Method
+	})
+	router.POST("/b/:x", func(c *Context) {
+		assert.Equal(t, "7", c.Param("x"))
+		assert.Empty(t, c.Param("id"))
+	})
+
+	PerformRequest(router, "GET", "/a/42")
+	assert.Equal(t, "42", id)
+	assert.Equal(t, "/a/42", path)
+	assert.Equal(t, "GET", method)
+}
+
+func TestContextHandlerName(t *testing.T) {
+	c, _ := CreateTestContext(httptest.NewRecorder())
+	assert.Empty(t, c.HandlerName())
+	assert.Empty(t, c.HandlerNames())
+
+	c.HandlersChain = HandlersChain{handlerNameTest, func(c *Context) {}, handlerNameTest2}
+	assert.Equal(t, "github.com/MichaelDeSteven/rum.handlerNameTest2", c.HandlerName())
+
+	names := c.HandlerNames()
+	if assert.Len(t, names, 3) {
+		assert.Equal(t, "github.com/MichaelDeSteven/rum.handlerNameTest", names[0])
+		assert.Regexp(t, `^github.com/MichaelDeSteven/rum\.TestContextHandlerName\.func1$`, names[1])
+		assert.Equal(t, "github.com/MichaelDeSteven/rum.handlerNameTest2", names[2])
+	}
+}
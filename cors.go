@@ -0,0 +1,149 @@
+package rum
+
+import (
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// CORSConfig configures the CORS middleware.
+type CORSConfig struct {
+	// AllowOrigins lists the allowed origins, such as
+	// "https://example.com". "*" allows every origin and a single wildcard
+	// allows the subdomains of a domain, e.g. "https://*.example.com".
+	AllowOrigins []string
+
+	// AllowOriginFunc, when set, is called with the origins not listed in
+	// AllowOrigins and allows them if it returns true.
+	AllowOriginFunc func(origin string) bool
+
+	// AllowMethods lists the methods allowed in cross-origin requests,
+	// GET, POST, PUT, PATCH, DELETE and HEAD when empty.
+	AllowMethods []string
+
+	// AllowHeaders lists the request headers allowed in cross-origin
+	// requests. When empty, the headers requested by the preflight request
+	// are allowed.
+	AllowHeaders []string
+
+	// ExposeHeaders lists the response headers readable by the client.
+	ExposeHeaders []string
+
+	// AllowCredentials allows requests with cookies or HTTP authentication.
+	AllowCredentials bool
+
+	// MaxAge is how long the result of a preflight request can be cached.
+	MaxAge time.Duration
+}
+
+// CORS returns a middleware implementing Cross-Origin Resource Sharing. It
+// answers the preflight requests with 204 and aborts the chain, even for the
+// paths only having routes for other methods, provided it is registered with
+// Engine.Use. Preflight requests from disallowed origins are answered with
+// 403; other requests from disallowed origins go on without CORS headers.
+func CORS(cfg CORSConfig) HandlerFunc {
+	allowAll := false
+	var exact []string
+	var wildcards [][2]string
+	for _, o := range cfg.AllowOrigins {
+		switch i := strings.IndexByte(o, '*'); {
+		case o == "*":
+			allowAll = true
+		case i >= 0:
+			wildcards = append(wildcards, [2]string{strings.ToLower(o[:i]), strings.ToLower(o[i+1:])})
+		default:
+			exact = append(exact, strings.ToLower(o))
+		}
+	}
+	allowOrigin := func(origin string) bool {
+		if allowAll {
+			return true
+		}
+		lower := strings.ToLower(origin)
+		for _, o := range exact {
+			if lower == o {
+				return true
+			}
+		}
+		for _, w := range wildcards {
+			if len(lower) > len(w[0])+len(w[1]) && strings.HasPrefix(lower, w[0]) && strings.HasSuffix(lower, w[1]) {
+				return true
+			}
+		}
+		return cfg.AllowOriginFunc != nil && cfg.AllowOriginFunc(origin)
+	}
+
+	methods := cfg.AllowMethods
+	if len(methods) == 0 {
+		methods = []string{http.MethodGet, http.MethodPost, http.MethodPut, http.MethodPatch, http.MethodDelete, http.MethodHead}
+	}
+	allowMethods := strings.ToUpper(strings.Join(methods, ", "))
+	allowHeaders := canonicalHeaders(cfg.AllowHeaders)
+	exposeHeaders := canonicalHeaders(cfg.ExposeHeaders)
+	maxAge := ""
+	if cfg.MaxAge > 0 {
+		maxAge = strconv.FormatInt(int64(cfg.MaxAge/time.Second), 10)
+	}
+
+	return func(c *Context) {
+		origin := c.requestHeader("Origin")
+		if origin == "" {
+			c.Next()
+			return
+		}
+		preflight := c.Method == http.MethodOptions && c.requestHeader("Access-Control-Request-Method") != ""
+		// the paths without any route are answered with 404
+		if preflight && c.FullPath() == "" && c.engine != nil && len(c.engine.allowedMethods(c.Path)) == 0 {
+			preflight = false
+		}
+
+		h := c.Writer.Header()
+		h.Add("Vary", "Origin")
+		if !allowOrigin(origin) {
+			if preflight {
+				c.AbortWithStatus(http.StatusForbidden)
+				return
+			}
+			c.Next()
+			return
+		}
+		if allowAll && !cfg.AllowCredentials {
+			h.Set("Access-Control-Allow-Origin", "*")
+		} else {
+			h.Set("Access-Control-Allow-Origin", origin)
+		}
+		if cfg.AllowCredentials {
+			h.Set("Access-Control-Allow-Credentials", "true")
+		}
+
+		if !preflight {
+			if exposeHeaders != "" {
+				h.Set("Access-Control-Expose-Headers", exposeHeaders)
+			}
+			c.Next()
+			return
+		}
+
+		h.Add("Vary", "Access-Control-Request-Method")
+		h.Add("Vary", "Access-Control-Request-Headers")
+		h.Set("Access-Control-Allow-Methods", allowMethods)
+		if allowHeaders != "" {
+			h.Set("Access-Control-Allow-Headers", allowHeaders)
+		} else if requested := c.requestHeader("Access-Control-Request-Headers"); requested != "" {
+			h.Set("Access-Control-Allow-Headers", requested)
+		}
+		if maxAge != "" {
+			h.Set("Access-Control-Max-Age", maxAge)
+		}
+		c.AbortWithStatus(http.StatusNoContent)
+	}
+}
+
+func canonicalHeaders(headers []string) string {
+	canonical := make([]string, len(headers))
+	for i, h := range headers {
+		canonical[i] = http.CanonicalHeaderKey(strings.TrimSpace(h))
+	}
+	return strings.Join(canonical, ", ")
+}
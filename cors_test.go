@@ -0,0 +1,124 @@
+package rum
+
+import (
+	"net/http"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func corsRouter(cfg CORSConfig) *Engine {
+	router := New(":9678")
+	router.Use(CORS(cfg))
+	router.GET("/users/:id", func(c *Context) {
+		c.String(http.StatusOK, "user")
+	})
+	router.POST("/users", func(c *Context) {
+		c.String(http.StatusCreated, "created")
+	})
+	return router
+}
+
+func preflight(origin, method string) []requestOption {
+	return []requestOption{
+		header{"Origin", origin},
+		header{"Access-Control-Request-Method", method},
+		header{"Access-Control-Request-Headers", "content-type,x-token"},
+	}
+}
+
+func TestCORSPreflight(t *testing.T) {
+	router := corsRouter(CORSConfig{
+		AllowOrigins:     []string{"https://example.com"},
+		AllowMethods:     []string{"get", "post"},
+		AllowHeaders:     []string{"content-type", "x-token"},
+		AllowCredentials: true,
+		MaxAge:           12 * time.Hour,
+	})
+
+	// only GET and POST routes exist for these paths
+	for _, path := range []string{"/users/42", "/users"} {
+		w := PerformRequest(router, "OPTIONS", path, preflight("https://example.com", "POST")...)
+		assert.Equal(t, http.StatusNoContent, w.Code, path)
+		assert.Empty(t, w.Body.String())
+		assert.Equal(t, "https://example.com", w.Header().Get("Access-Control-Allow-Origin"))
+		assert.Equal(t, "true", w.Header().Get("Access-Control-Allow-Credentials"))
+		assert.Equal(t, "GET, POST", w.Header().Get("Access-Control-Allow-Methods"))
+		assert.Equal(t, "Content-Type, X-Token", w.Header().Get("Access-Control-Allow-Headers"))
+		assert.Equal(t, "43200", w.Header().Get("Access-Control-Max-Age"))
+		assert.Equal(t, []string{"Origin", "Access-Control-Request-Method", "Access-Control-Request-Headers"}, w.Header().Values("Vary"))
+	}
+
+	w := PerformRequest(router, "OPTIONS", "/missing", preflight("https://example.com", "GET")...)
+	assert.Equal(t, http.StatusNotFound, w.Code)
+
+	w = PerformRequest(router, "OPTIONS", "/users", preflight("https://evil.com", "POST")...)
+	assert.Equal(t, http.StatusForbidden, w.Code)
+	assert.Empty(t, w.Header().Get("Access-Control-Allow-Origin"))
+
+	// an OPTIONS request without Access-Control-Request-Method is no preflight
+	w = PerformRequest(router, "OPTIONS", "/users", header{"Origin", "https://example.com"})
+	assert.Equal(t, http.StatusNotFound, w.Code)
+}
+
+func TestCORSActualRequest(t *testing.T) {
+	router := corsRouter(CORSConfig{
+		AllowOrigins:  []string{"*"},
+		ExposeHeaders: []string{"x-total-count"},
+	})
+
+	w := PerformRequest(router, "GET", "/users/1", header{"Origin", "https://any.org"})
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Equal(t, "user", w.Body.String())
+	assert.Equal(t, "*", w.Header().Get("Access-Control-Allow-Origin"))
+	assert.Equal(t, "X-Total-Count", w.Header().Get("Access-Control-Expose-Headers"))
+	assert.Empty(t, w.Header().Get("Access-Control-Allow-Credentials"))
+
+	// same-origin requests have no Origin header
+	w = PerformRequest(router, "GET", "/users/1")
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Empty(t, w.Header().Get("Access-Control-Allow-Origin"))
+	assert.Empty(t, w.Header().Get("Vary"))
+
+	// the requested headers are allowed when AllowHeaders is empty
+	w = PerformRequest(router, "OPTIONS", "/users/1", preflight("https://any.org", "GET")...)
+	assert.Equal(t, http.StatusNoContent, w.Code)
+	assert.Equal(t, "content-type,x-token", w.Header().Get("Access-Control-Allow-Headers"))
+	assert.Equal(t, "GET, POST, PUT, PATCH, DELETE, HEAD", w.Header().Get("Access-Control-Allow-Methods"))
+	assert.Empty(t, w.Header().Get("Access-Control-Max-Age"))
+}
+
+func TestCORSOrigins(t *testing.T) {
+	router := corsRouter(CORSConfig{
+		AllowOrigins:     []string{"https://example.com", "https://*.example.org"},
+		AllowOriginFunc:  func(origin string) bool { return strings.HasSuffix(origin, ".internal") },
+		AllowCredentials: true,
+	})
+
+	for origin, allowed := range map[string]bool{
+		"https://example.com":      true,
+		"https://EXAMPLE.com":      true,
+		"https://api.example.org":  true,
+		"https://a.b.example.org":  true,
+		"https://example.org":      false,
+		"http://api.example.org":   false,
+		"https://example.com.evil": false,
+		"http://svc.internal":      true,
+		"https://other.com":        false,
+	} {
+		w := PerformRequest(router, "GET", "/users/1", header{"Origin", origin})
+		assert.Equal(t, http.StatusOK, w.Code, origin)
+		if allowed {
+			assert.Equal(t, origin, w.Header().Get("Access-Control-Allow-Origin"), origin)
+		} else {
+			assert.Empty(t, w.Header().Get("Access-Control-Allow-Origin"), origin)
+		}
+	}
+
+	// with credentials the origin is echoed instead of "*"
+	router = corsRouter(CORSConfig{AllowOrigins: []string{"*"}, AllowCredentials: true})
+	w := PerformRequest(router, "GET", "/users/1", header{"Origin", "https://any.org"})
+	assert.Equal(t, "https://any.org", w.Header().Get("Access-Control-Allow-Origin"))
+}
@@ -0,0 +1,133 @@
+package rum
+
+import (
+	"compress/gzip"
+	"compress/zlib"
+	"io"
+	"net/http"
+	"sort"
+	"strings"
+)
+
+// DefaultDecompressMaxBytes is the default limit of the decompressed request
+// bodies.
+const DefaultDecompressMaxBytes = 32 << 20
+
+// DecoderFunc returns a reader decompressing r.
+type DecoderFunc func(r io.Reader) (io.ReadCloser, error)
+
+// defaultDecoders are the content codings Decompress always supports.
+var defaultDecoders = map[string]DecoderFunc{
+	"gzip": func(r io.Reader) (io.ReadCloser, error) {
+		return gzip.NewReader(r)
+	},
+	"deflate": zlib.NewReader,
+}
+
+// DecompressConfig configures the Decompress middleware.
+type DecompressConfig struct {
+	// MaxBytes limits the size of the decompressed bodies,
+	// DefaultDecompressMaxBytes when 0.
+	MaxBytes int64
+
+	// Decoders adds the decoders of other content codings, such as "br",
+	// or replaces those of "gzip" and "deflate".
+	Decoders map[string]DecoderFunc
+}
+
+// Decompress returns a middleware decompressing the request bodies sent with
+// a Content-Encoding header, so that the bindings read the plain body. The
+// Content-Encoding and Content-Length headers are removed from the request.
+//
+// Reading more than MaxBytes decompressed bytes fails with ErrBodyTooLarge
+// and the request is answered with 413, as with MaxBodyBytes which, used
+// after Decompress, replaces the limit. Engine.MaxBodyBytes limits the
+// compressed body. The requests whose content coding is unknown are answered
+// with 415.
+func Decompress(cfg DecompressConfig) HandlerFunc {
+	if cfg.MaxBytes == 0 {
+		cfg.MaxBytes = DefaultDecompressMaxBytes
+	}
+	decoders := make(map[string]DecoderFunc, len(defaultDecoders)+len(cfg.Decoders))
+	for encoding, fn := range defaultDecoders {
+		decoders[encoding] = fn
+	}
+	for encoding, fn := range cfg.Decoders {
+		decoders[strings.ToLower(encoding)] = fn
+	}
+	supported := make([]string, 0, len(decoders))
+	for encoding := range decoders {
+		supported = append(supported, encoding)
+	}
+	sort.Strings(supported)
+	acceptEncoding := strings.Join(supported, ", ")
+
+	return func(c *Context) {
+		header := c.requestHeader("Content-Encoding")
+		if header == "" || c.Request.Body == nil || c.Request.Body == http.NoBody {
+			c.Next()
+			return
+		}
+
+		// the codings are listed in the order they were applied
+		var chain []DecoderFunc
+		for _, encoding := range strings.Split(header, ",") {
+			encoding = strings.ToLower(strings.TrimSpace(encoding))
+			if encoding == "" || encoding == "identity" {
+				continue
+			}
+			fn, ok := decoders[encoding]
+			if !ok {
+				c.SetHeader("Accept-Encoding", acceptEncoding)
+				c.AbortWithStatus(http.StatusUnsupportedMediaType)
+				return
+			}
+			chain = append(chain, fn)
+		}
+		if len(chain) > 0 {
+			c.Request.Header.Del("Content-Encoding")
+			c.Request.Header.Del("Content-Length")
+			c.Request.ContentLength = -1
+			c.Request.Body = &decodedBody{body: c.Request.Body, chain: chain}
+			c.limitBody(cfg.MaxBytes)
+		}
+		c.Next()
+	}
+}
+
+// decodedBody decompresses a request body, creating the decoders on the
+// first read so that an invalid body fails as a read error.
+type decodedBody struct {
+	body    io.ReadCloser
+	chain   []DecoderFunc
+	r       io.Reader
+	closers []io.Closer
+	err     error
+}
+
+func (b *decodedBody) Read(p []byte) (int, error) {
+	if b.r == nil && b.err == nil {
+		var r io.Reader = b.body
+		for i := len(b.chain) - 1; i >= 0; i-- {
+			d, err := b.chain[i](r)
+			if err != nil {
+				b.err = err
+				break
+			}
+			b.closers = append(b.closers, d)
+			r = d
+		}
+		b.r = r
+	}
+	if b.err != nil {
+		return 0, b.err
+	}
+	return b.r.Read(p)
+}
+
+func (b *decodedBody) Close() error {
+	for i := len(b.closers) - 1; i >= 0; i-- {
+		b.closers[i].Close()
+	}
+	return b.body.Close()
+}
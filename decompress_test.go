@@ -0,0 +1,148 @@
+package rum
+
+import (
+	"bytes"
+	"compress/gzip"
+	"compress/zlib"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"strings"
+	"testing"
+
+	"github.com/MichaelDeSteven/rum/binding"
+	"github.com/stretchr/testify/assert"
+)
+
+func compressBody(t *testing.T, encoding, body string) []byte {
+	var buf bytes.Buffer
+	var w io.WriteCloser
+	switch encoding {
+	case "gzip":
+		w = gzip.NewWriter(&buf)
+	case "deflate":
+		w = zlib.NewWriter(&buf)
+	}
+	_, err := w.Write([]byte(body))
+	assert.NoError(t, err)
+	assert.NoError(t, w.Close())
+	return buf.Bytes()
+}
+
+type decompressUser struct {
+	Name string `json:"name" form:"name"`
+}
+
+func TestDecompress(t *testing.T) {
+	router := New(":9678")
+	router.Use(Decompress(DecompressConfig{}))
+	var user decompressUser
+	var err error
+	var contentEncoding string
+	router.POST("/json", func(c *Context) {
+		user = decompressUser{}
+		err = c.ShouldBindJSON(&user)
+		contentEncoding = c.requestHeader("Content-Encoding")
+	})
+	router.POST("/body", func(c *Context) {
+		user = decompressUser{}
+		err = c.ShouldBindBodyWith(&user, binding.JSON)
+		if err == nil {
+			err = c.ShouldBindBodyWith(&user, binding.JSON)
+		}
+	})
+
+	for _, encoding := range []string{"gzip", "deflate", "GZIP", "deflate, gzip"} {
+		body := []byte(`{"name": "rum"}`)
+		for _, e := range strings.Split(encoding, ",") {
+			body = compressBody(t, strings.ToLower(strings.TrimSpace(e)), string(body))
+		}
+		for _, path := range []string{"/json", "/body"} {
+			w := PerformRequest(router, "POST", path, header{"Content-Encoding", encoding}, withBody(body))
+			assert.Equal(t, http.StatusOK, w.Code, encoding)
+			assert.NoError(t, err, encoding)
+			assert.Equal(t, "rum", user.Name, encoding)
+		}
+		assert.Empty(t, contentEncoding)
+	}
+
+	// plain bodies are read as is
+	w := PerformRequest(router, "POST", "/json", withBody([]byte(`{"name": "plain"}`)))
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Equal(t, "plain", user.Name)
+
+	w = PerformRequest(router, "POST", "/json", header{"Content-Encoding", "identity"}, withBody([]byte(`{"name": "identity"}`)))
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Equal(t, "identity", user.Name)
+
+	// an invalid body fails as a read error
+	w = PerformRequest(router, "POST", "/json", header{"Content-Encoding", "gzip"}, withBody([]byte(`{"name": "rum"}`)))
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.ErrorIs(t, err, gzip.ErrHeader)
+
+	w = PerformRequest(router, "POST", "/json", header{"Content-Encoding", "br"}, withBody([]byte("...")))
+	assert.Equal(t, http.StatusUnsupportedMediaType, w.Code)
+	assert.Equal(t, "deflate, gzip", w.Header().Get("Accept-Encoding"))
+}
+
+func TestDecompressMaxBytes(t *testing.T) {
+	router := New(":9678")
+	router.Use(Decompress(DecompressConfig{MaxBytes: 1024}))
+	var body []byte
+	var err error
+	read := func(c *Context) {
+		body, err = ioutil.ReadAll(c.Request.Body)
+	}
+	router.POST("/", read)
+	router.POST("/large", MaxBodyBytes(1<<20), read)
+
+	// a tiny body inflating to 1 MB
+	bomb := compressBody(t, "gzip", strings.Repeat("0", 1<<20))
+	assert.Less(t, len(bomb), 4096)
+
+	w := PerformRequest(router, "POST", "/", header{"Content-Encoding", "gzip"}, withBody(bomb))
+	assert.ErrorIs(t, err, ErrBodyTooLarge)
+	assert.Equal(t, http.StatusRequestEntityTooLarge, w.Code)
+
+	w = PerformRequest(router, "POST", "/large", header{"Content-Encoding", "gzip"}, withBody(bomb))
+	assert.NoError(t, err)
+	assert.Len(t, body, 1<<20)
+	assert.Equal(t, http.StatusOK, w.Code)
+
+	// Engine.MaxBodyBytes limits the compressed body
+	router = New(":9678")
+	router.MaxBodyBytes = 64
+	router.Use(Decompress(DecompressConfig{}))
+	router.POST("/", read)
+	w = PerformRequest(router, "POST", "/", header{"Content-Encoding", "gzip"}, withBody(bomb))
+	assert.ErrorIs(t, err, ErrBodyTooLarge)
+	assert.Equal(t, http.StatusRequestEntityTooLarge, w.Code)
+}
+
+func TestDecompressDecoders(t *testing.T) {
+	upper := func(r io.Reader) (io.ReadCloser, error) {
+		b, err := ioutil.ReadAll(r)
+		return ioutil.NopCloser(bytes.NewReader(bytes.ToUpper(b))), err
+	}
+	router := New(":9678")
+	router.Use(Decompress(DecompressConfig{Decoders: map[string]DecoderFunc{"X-Upper": upper}}))
+	var body []byte
+	router.POST("/", func(c *Context) {
+		body, _ = ioutil.ReadAll(c.Request.Body)
+	})
+
+	w := PerformRequest(router, "POST", "/", header{"Content-Encoding", "x-upper"}, withBody([]byte("rum")))
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Equal(t, "RUM", string(body))
+
+	w = PerformRequest(router, "POST", "/", header{"Content-Encoding", "gzip"}, withBody(compressBody(t, "gzip", "rum")))
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Equal(t, "rum", string(body))
+
+	// the decoders of a middleware are not shared with the others
+	router = New(":9678")
+	router.Use(Decompress(DecompressConfig{}))
+	w = PerformRequest(router, "POST", "/", header{"Content-Encoding", "x-upper"}, withBody([]byte("rum")))
+	assert.Equal(t, http.StatusUnsupportedMediaType, w.Code)
+	assert.Equal(t, "deflate, gzip", w.Header().Get("Accept-Encoding"))
+}
@@ -1,6 +1,7 @@
 package rum
 
 import (
+	"net"
 	"net/http"
 	"sync"
 )
@@ -39,20 +40,66 @@ type Engine struct {
 	group *RouterGroup
 
 	pool sync.Pool
+
+	// allNoRoute is the chain of the requests matching no route: the global
+	// middleware followed by NotFound
+	allNoRoute HandlersChain
+
+	// MaxMultipartMemory is the maximum number of bytes of a multipart form
+	// kept in memory, the remaining parts are stored in temporary files.
+	MaxMultipartMemory int64
+
+	// MaxBodyBytes limits the size of the request bodies, 0 means no limit.
+	// See MaxBodyBytes to set the limit of some routes.
+	MaxBodyBytes int64
+
+	// RemoteIPHeaders lists the headers Context.ClientIP reads the client
+	// IP from, in order, when the request comes from a trusted proxy.
+	RemoteIPHeaders []string
+
+	// TrustedPlatform is the header set by the platform the engine runs on
+	// with the client IP, such as PlatformCloudflare. Context.ClientIP
+	// trusts it whatever the proxies. Only set it when the platform can not
+	// be bypassed, as clients can send it too.
+	TrustedPlatform string
+
+	// trustedCIDRs are the networks of the trusted proxies, see
+	// SetTrustedProxies
+	trustedCIDRs []*net.IPNet
 }
 
 func (engine *Engine) allocateContext() *Context {
 	v := make(Params, 0, maxParams)
-	return &Context{Params: v, index: -1}
+	return &Context{engine: engine, Params: v, index: -1}
 }
 
 func (e *Engine) ServeHTTP(w http.ResponseWriter, r *http.Request) {
 	c := e.pool.Get().(*Context)
 	c.reset(w, r)
+	if e.MaxBodyBytes > 0 {
+		c.limitBody(e.MaxBodyBytes)
+	}
 	e.handle(c)
+	c.release()
 	e.pool.Put(c)
 }
 
+// HandleContext re-enters a context that has been rewritten, typically by
+// changing c.Request.URL.Path, and dispatches it through the router again.
+// It can be used for internal forwards; the caller's chain resumes afterwards
+// with its route, params, path and method restored.
+func (e *Engine) HandleContext(c *Context) {
+	oldIndex, oldHandlers, oldFullPath := c.index, c.HandlersChain, c.fullPath
+	oldPath, oldMethod := c.Path, c.Method
+	// the forwarded route reuses the array of c.Params
+	oldParams := append(Params(nil), c.Params...)
+	c.resetRoute(c.Request)
+	e.handle(c)
+	c.index, c.HandlersChain, c.fullPath = oldIndex, oldHandlers, oldFullPath
+	c.Path, c.Method = oldPath, oldMethod
+	c.Params = append(c.Params[:0], oldParams...)
+}
+
 func (e *Engine) addRoute(method, path string, handlers HandlersChain) {
 	assert1(path[0] == '/', "path must begin with '/'")
 	assert1(method != "", "HTTP method can not be empty")
@@ -66,8 +113,20 @@ func (e *Engine) addRoute(method, path string, handlers HandlersChain) {
 	root.addRoute(path, handlers)
 }
 
+// Use attaches global middleware to the engine. The middleware runs for the
+// routes registered afterwards, and also for the requests matching no route,
+// before NotFound answers them with 404, e.g. for CORS to answer preflight
+// requests. This includes the requests whose path is only registered for
+// other methods. Middleware such as Metrics, RateLimit, Tracing or Compress
+// therefore sees these requests too, Context.FullPath returns "" for them.
+//
+// Use panics if the chains would hold too many handlers, leaving the engine
+// unchanged.
 func (e *Engine) Use(middleware ...HandlerFunc) IRoutes {
-	return e.group.Use(middleware...)
+	assert1(len(e.group.Handlers)+len(middleware)+1 < abortInx, "too many handlers")
+	e.group.Use(middleware...)
+	e.allNoRoute = e.group.combine(HandlersChain{NotFound})
+	return e
 }
 
 func (e *Engine) Group(relativePath string, handlers ...HandlerFunc) *RouterGroup {
@@ -100,8 +159,11 @@ func (e *Engine) Handle(method, path string, handlers ...HandlerFunc) IRoutes {
 
 func New(addr string) *Engine {
 	engine := &Engine{
-		addr:  addr,
-		trees: make(trees, 0),
+		addr:               addr,
+		trees:              make(trees, 0),
+		MaxMultipartMemory: defaultMultipartMemory,
+		allNoRoute:         HandlersChain{NotFound},
+		RemoteIPHeaders:    []string{"X-Forwarded-For", "X-Real-IP"},
 		group: &RouterGroup{
 			BasePath: "/",
 			root:     true,
@@ -127,21 +189,34 @@ func (e *Engine) Start() {
 }
 
 func (e *Engine) handle(c *Context) {
-	tree := e.trees.get(c.Method)
-	if tree == nil {
-		NotFound(c)
-		return
-	}
-	handlers, params := tree.getValue(c.Path, &c.Params)
-	c.HandlersChain = handlers
-	if params != nil {
-		c.Params = *params
+	if tree := e.trees.get(c.Method); tree != nil {
+		handlers, params, fullPath := tree.getValue(c.Path, &c.Params)
+		c.HandlersChain = handlers
+		c.fullPath = fullPath
+		if params != nil {
+			c.Params = *params
+		}
 	}
+	// the global middleware also runs for the requests matching no route
 	if c.HandlersChain == nil {
-		NotFound(c)
-	} else {
-		c.Next()
+		c.HandlersChain = e.allNoRoute
+	}
+	c.Next()
+	if c.bodyTooLarge() && !c.Writer.Written() {
+		c.Status(http.StatusRequestEntityTooLarge)
+	}
+	c.writermem.WriteHeaderNow()
+}
+
+// allowedMethods returns the methods having a route matching path.
+func (e *Engine) allowedMethods(path string) []string {
+	var methods []string
+	for _, tree := range e.trees {
+		if handlers, _, _ := tree.root.getValue(path, nil); handlers != nil {
+			methods = append(methods, tree.method)
+		}
 	}
+	return methods
 }
 
 func NotFound(c *Context) {
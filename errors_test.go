@@ -0,0 +1,63 @@
+package rum
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestError(t *testing.T) {
+	baseError := errors.New("test error")
+	err := &Error{
+		Err:  baseError,
+		Type: ErrorTypePrivate,
+	}
+	assert.Equal(t, err.Error(), baseError.Error())
+	assert.True(t, errors.Is(err, baseError))
+
+	assert.Equal(t, err.SetType(ErrorTypePublic), err)
+	assert.Equal(t, ErrorTypePublic, err.Type)
+	assert.True(t, err.IsType(ErrorTypePublic))
+	assert.False(t, err.IsType(ErrorTypePrivate))
+
+	assert.Equal(t, err.SetMeta("some data"), err)
+	assert.Equal(t, "some data", err.Meta)
+}
+
+func TestErrorSlice(t *testing.T) {
+	errs := errorMsgs{
+		{Err: errors.New("first"), Type: ErrorTypePrivate},
+		{Err: errors.New("second"), Type: ErrorTypePrivate, Meta: "some data"},
+		{Err: errors.New("third"), Type: ErrorTypeBind},
+	}
+
+	assert.Equal(t, errs, errs.ByType(ErrorTypeAny))
+	assert.Equal(t, "third", errs.Last().Error())
+	assert.Equal(t, []string{"first", "second", "third"}, errs.Errors())
+	assert.Equal(t, []string{"third"}, errs.ByType(ErrorTypeBind).Errors())
+	assert.Equal(t, []string{"first", "second"}, errs.ByType(ErrorTypePrivate).Errors())
+	assert.Equal(t, "Error #01: first\nError #02: second\n     Meta: some data\nError #03: third\n", errs.String())
+
+	errs = errorMsgs{}
+	assert.Nil(t, errs.Last())
+	assert.Nil(t, errs.Errors())
+	assert.Nil(t, errs.ByType(ErrorTypeAny))
+	assert.Empty(t, errs.String())
+}
+
+func TestContextError(t *testing.T) {
+	c, _ := CreateTestContext(nil)
+	assert.Empty(t, c.Errors)
+
+	firstErr := errors.New("first error")
+	c.Error(firstErr)
+	assert.Len(t, c.Errors, 1)
+	assert.Equal(t, ErrorTypePrivate, c.Errors.Last().Type)
+
+	c.Error(&Error{Err: errors.New("second error"), Type: ErrorTypePublic})
+	assert.Len(t, c.Errors, 2)
+	assert.Equal(t, ErrorTypePublic, c.Errors.Last().Type)
+
+	assert.Panics(t, func() { c.Error(nil) })
+}
@@ -6,5 +6,9 @@ require (
 	github.com/go-playground/validator/v10 v10.9.0
 	github.com/goccy/go-json v0.9.4
 	github.com/json-iterator/go v1.1.12
-	github.com/stretchr/testify v1.7.0
+	github.com/pelletier/go-toml/v2 v2.0.1
+	github.com/stretchr/testify v1.7.1
+	github.com/ugorji/go/codec v1.2.7
+	google.golang.org/protobuf v1.28.0
+	gopkg.in/yaml.v2 v2.4.0
 )
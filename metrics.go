@@ -0,0 +1,307 @@
+package rum
+
+import (
+	"bytes"
+	"math"
+	"net/http"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// DefaultLatencyBuckets are the default buckets of the request latency
+// histogram, in seconds.
+var DefaultLatencyBuckets = []float64{.005, .01, .025, .05, .1, .25, .5, 1, 2.5, 5, 10}
+
+// DefaultSizeBuckets are the default buckets of the response size histogram,
+// in bytes.
+var DefaultSizeBuckets = []float64{100, 1000, 10000, 100000, 1e6, 1e7, 1e8}
+
+// MetricsConfig configures the metrics collected by NewMetrics.
+type MetricsConfig struct {
+	// Namespace prefixes the name of the metrics, "rum" when empty.
+	Namespace string
+
+	// Path is the route serving the metrics, "/metrics" when empty.
+	Path string
+
+	// LatencyBuckets are the upper bounds of the latency histogram buckets,
+	// DefaultLatencyBuckets when empty.
+	LatencyBuckets []float64
+
+	// SizeBuckets are the upper bounds of the response size histogram
+	// buckets, DefaultSizeBuckets when empty.
+	SizeBuckets []float64
+}
+
+// Metrics collects request metrics and exposes them in the Prometheus text
+// exposition format:
+//
+//	<namespace>_http_requests_total            counter
+//	<namespace>_http_request_duration_seconds  histogram
+//	<namespace>_http_response_size_bytes       histogram
+//	<namespace>_http_requests_in_flight        gauge
+//
+// The requests are labeled by method, route pattern (see Context.FullPath)
+// and status class, e.g. "2xx"; the in-flight gauge has no status. As the
+// labels must not be chosen by the clients, the methods not defined by
+// RFC 9110 and RFC 5789 are labeled "OTHER", and the requests matching no
+// route are labeled with the route "unmatched".
+type Metrics struct {
+	path     string
+	requests *metricFamily
+	latency  *metricFamily
+	size     *metricFamily
+	inFlight *metricFamily
+}
+
+// NewMetrics returns Metrics configured by cfg.
+func NewMetrics(cfg MetricsConfig) *Metrics {
+	if cfg.Namespace == "" {
+		cfg.Namespace = "rum"
+	}
+	if cfg.Path == "" {
+		cfg.Path = "/metrics"
+	}
+	if len(cfg.LatencyBuckets) == 0 {
+		cfg.LatencyBuckets = DefaultLatencyBuckets
+	}
+	if len(cfg.SizeBuckets) == 0 {
+		cfg.SizeBuckets = DefaultSizeBuckets
+	}
+	name := cfg.Namespace + "_http_"
+	return &Metrics{
+		path: cfg.Path,
+		requests: newMetricFamily(name+"requests_total", "counter",
+			"Total number of HTTP requests.", nil),
+		latency: newMetricFamily(name+"request_duration_seconds", "histogram",
+			"Duration of HTTP requests in seconds.", sortedBuckets(cfg.LatencyBuckets)),
+		size: newMetricFamily(name+"response_size_bytes", "histogram",
+			"Size of HTTP response bodies in bytes.", sortedBuckets(cfg.SizeBuckets)),
+		inFlight: newMetricFamily(name+"requests_in_flight", "gauge",
+			"Number of HTTP requests being served.", nil),
+	}
+}
+
+// Register adds the middleware of m to e and serves the metrics on the
+// configured path. As for any middleware, only the routes registered after
+// Register are measured.
+func (m *Metrics) Register(e *Engine) {
+	e.Use(m.Middleware())
+	e.GET(m.path, m.Handler())
+}
+
+// Middleware returns a middleware measuring the requests.
+func (m *Metrics) Middleware() HandlerFunc {
+	return func(c *Context) {
+		method, route := metricMethod(c.Method), c.FullPath()
+		if route == "" {
+			route = unmatchedRoute
+		}
+		flight := m.inFlight.series(method, route)
+		flight.add(1)
+		start := time.Now()
+
+		c.Next()
+
+		flight.add(-1)
+		status := statusClass(c.Writer.Status())
+		size := c.Writer.Size()
+		if size < 0 {
+			size = 0
+		}
+		m.requests.series(method, route, status).add(1)
+		m.latency.series(method, route, status).observe(time.Since(start).Seconds())
+		m.size.series(method, route, status).observe(float64(size))
+	}
+}
+
+// Handler returns a handler writing the metrics in the Prometheus text
+// exposition format.
+func (m *Metrics) Handler() HandlerFunc {
+	return func(c *Context) {
+		var buf bytes.Buffer
+		m.write(&buf)
+		c.SetHeader("Content-Type", "text/plain; version=0.0.4; charset=utf-8")
+		c.Data(http.StatusOK, buf.Bytes())
+	}
+}
+
+func (m *Metrics) write(buf *bytes.Buffer) {
+	for _, f := range []*metricFamily{m.requests, m.latency, m.size, m.inFlight} {
+		f.write(buf)
+	}
+}
+
+// unmatchedRoute is the route label of the requests matching no route.
+const unmatchedRoute = "unmatched"
+
+// metricMethod returns the method label of a request method.
+func metricMethod(method string) string {
+	switch method {
+	case http.MethodGet, http.MethodHead, http.MethodPost, http.MethodPut, http.MethodPatch,
+		http.MethodDelete, http.MethodConnect, http.MethodOptions, http.MethodTrace:
+		return method
+	}
+	return "OTHER"
+}
+
+func statusClass(code int) string {
+	return strconv.Itoa(code/100) + "xx"
+}
+
+func sortedBuckets(buckets []float64) []float64 {
+	b := append([]float64(nil), buckets...)
+	sort.Float64s(b)
+	return b
+}
+
+var metricLabels = []string{"method", "route", "status"}
+
+// metricFamily holds the series of a metric, one per set of label values.
+type metricFamily struct {
+	name    string
+	typ     string
+	help    string
+	buckets []float64
+
+	mu sync.RWMutex
+	m  map[string]*metricSeries
+}
+
+func newMetricFamily(name, typ, help string, buckets []float64) *metricFamily {
+	return &metricFamily{
+		name:    name,
+		typ:     typ,
+		help:    help,
+		buckets: buckets,
+		m:       make(map[string]*metricSeries),
+	}
+}
+
+// series returns the series of the given label values, in the order of
+// metricLabels, creating it if needed.
+func (f *metricFamily) series(values ...string) *metricSeries {
+	key := strings.Join(values, "\xff")
+	f.mu.RLock()
+	s, ok := f.m[key]
+	f.mu.RUnlock()
+	if ok {
+		return s
+	}
+
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	if s, ok = f.m[key]; !ok {
+		s = &metricSeries{labels: formatLabels(values), buckets: f.buckets}
+		if f.buckets != nil {
+			s.counts = make([]uint64, len(f.buckets))
+		}
+		f.m[key] = s
+	}
+	return s
+}
+
+func (f *metricFamily) write(buf *bytes.Buffer) {
+	f.mu.RLock()
+	series := make([]*metricSeries, 0, len(f.m))
+	for _, s := range f.m {
+		series = append(series, s)
+	}
+	f.mu.RUnlock()
+	sort.Slice(series, func(i, j int) bool {
+		return series[i].labels < series[j].labels
+	})
+
+	buf.WriteString("# HELP " + f.name + " " + f.help + "\n")
+	buf.WriteString("# TYPE " + f.name + " " + f.typ + "\n")
+	for _, s := range series {
+		s.mu.Lock()
+		if f.buckets == nil {
+			writeSample(buf, f.name, s.labels, "", s.value)
+		} else {
+			var cumulative uint64
+			for i, le := range f.buckets {
+				cumulative += s.counts[i]
+				writeSample(buf, f.name+"_bucket", s.labels, formatFloat(le), float64(cumulative))
+			}
+			writeSample(buf, f.name+"_bucket", s.labels, "+Inf", float64(s.count))
+			writeSample(buf, f.name+"_sum", s.labels, "", s.value)
+			writeSample(buf, f.name+"_count", s.labels, "", float64(s.count))
+		}
+		s.mu.Unlock()
+	}
+}
+
+// metricSeries is a counter or gauge value, or a histogram whose value is the
+// sum of the observations.
+type metricSeries struct {
+	labels  string
+	buckets []float64
+
+	mu     sync.Mutex
+	value  float64
+	counts []uint64
+	count  uint64
+}
+
+func (s *metricSeries) add(v float64) {
+	s.mu.Lock()
+	s.value += v
+	s.mu.Unlock()
+}
+
+func (s *metricSeries) observe(v float64) {
+	// the index of the first bucket v fits in
+	i := sort.SearchFloat64s(s.buckets, v)
+	s.mu.Lock()
+	if i < len(s.counts) {
+		s.counts[i]++
+	}
+	s.count++
+	s.value += v
+	s.mu.Unlock()
+}
+
+func writeSample(buf *bytes.Buffer, name, labels, le string, v float64) {
+	buf.WriteString(name)
+	if labels != "" || le != "" {
+		buf.WriteByte('{')
+		buf.WriteString(labels)
+		if le != "" {
+			if labels != "" {
+				buf.WriteByte(',')
+			}
+			buf.WriteString(`le="` + le + `"`)
+		}
+		buf.WriteByte('}')
+	}
+	buf.WriteByte(' ')
+	buf.WriteString(formatFloat(v))
+	buf.WriteByte('\n')
+}
+
+var labelEscaper = strings.NewReplacer(`\`, `\\`, `"`, `\"`, "\n", `\n`)
+
+func formatLabels(values []string) string {
+	var b strings.Builder
+	for i, v := range values {
+		if i > 0 {
+			b.WriteByte(',')
+		}
+		b.WriteString(metricLabels[i] + `="` + labelEscaper.Replace(v) + `"`)
+	}
+	return b.String()
+}
+
+func formatFloat(v float64) string {
+	switch {
+	case math.IsInf(v, 1):
+		return "+Inf"
+	case math.IsInf(v, -1):
+		return "-Inf"
+	}
+	return strconv.FormatFloat(v, 'g', -1, 64)
+}
@@ -0,0 +1,107 @@
+package rum
+
+import (
+	"net/http"
+	"strconv"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestMetrics(t *testing.T) {
+	router := New(":9678")
+	m := NewMetrics(MetricsConfig{
+		Namespace:      "test",
+		Path:           "/_metrics",
+		LatencyBuckets: []float64{10, 1},
+		SizeBuckets:    []float64{5, 100},
+	})
+	m.Register(router)
+	var inFlight string
+	router.GET("/users/:id", func(c *Context) {
+		inFlight = m.inFlight.series("GET", "/users/:id").labels
+		c.String(http.StatusOK, "hello")
+	})
+	router.POST("/users", func(c *Context) {
+		c.AbortWithStatus(http.StatusBadRequest)
+	})
+
+	PerformRequest(router, "GET", "/users/1")
+	PerformRequest(router, "GET", "/users/2")
+	PerformRequest(router, "POST", "/users")
+	PerformRequest(router, "GET", "/missing")
+	assert.Equal(t, `method="GET",route="/users/:id"`, inFlight)
+
+	w := PerformRequest(router, "GET", "/_metrics")
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Equal(t, "text/plain; version=0.0.4; charset=utf-8", w.Header().Get("Content-Type"))
+
+	body := w.Body.String()
+	for _, line := range []string{
+		"# HELP test_http_requests_total Total number of HTTP requests.",
+		"# TYPE test_http_requests_total counter",
+		`test_http_requests_total{method="GET",route="/users/:id",status="2xx"} 2`,
+		`test_http_requests_total{method="POST",route="/users",status="4xx"} 1`,
+		"# TYPE test_http_request_duration_seconds histogram",
+		`test_http_request_duration_seconds_bucket{method="GET",route="/users/:id",status="2xx",le="1"} 2`,
+		`test_http_request_duration_seconds_bucket{method="GET",route="/users/:id",status="2xx",le="10"} 2`,
+		`test_http_request_duration_seconds_bucket{method="GET",route="/users/:id",status="2xx",le="+Inf"} 2`,
+		`test_http_request_duration_seconds_count{method="GET",route="/users/:id",status="2xx"} 2`,
+		"# TYPE test_http_response_size_bytes histogram",
+		`test_http_response_size_bytes_bucket{method="GET",route="/users/:id",status="2xx",le="5"} 2`,
+		`test_http_response_size_bytes_sum{method="GET",route="/users/:id",status="2xx"} 10`,
+		`test_http_response_size_bytes_count{method="POST",route="/users",status="4xx"} 1`,
+		"# TYPE test_http_requests_in_flight gauge",
+		`test_http_requests_in_flight{method="GET",route="/users/:id"} 0`,
+		`test_http_requests_in_flight{method="GET",route="/_metrics"} 1`,
+	} {
+		assert.Contains(t, body, line+"\n")
+	}
+	assert.NotContains(t, body, "/users/1")
+	assert.NotContains(t, body, "/missing")
+
+	// the families keep their order and each series is sorted
+	assert.True(t, strings.Index(body, "test_http_requests_total") < strings.Index(body, "test_http_request_duration_seconds"))
+	assert.True(t, strings.Index(body, `route="/users/:id",status="2xx"} 2`) < strings.Index(body, `method="POST"`))
+}
+
+func TestMetricsLabelCardinality(t *testing.T) {
+	router := New(":9678")
+	m := NewMetrics(MetricsConfig{})
+	m.Register(router)
+	router.Handle("PURGE", "/cache", func(c *Context) {})
+	router.GET("/users/:id", func(c *Context) {})
+
+	for i := 0; i < 100; i++ {
+		PerformRequest(router, "FOO"+strconv.Itoa(i), "/users/1")
+		PerformRequest(router, "GET", "/missing/"+strconv.Itoa(i))
+	}
+	PerformRequest(router, "PURGE", "/cache")
+
+	for _, f := range []*metricFamily{m.requests, m.latency, m.size, m.inFlight} {
+		assert.Len(t, f.m, 3, f.name)
+	}
+	body := PerformRequest(router, "GET", "/metrics").Body.String()
+	assert.Contains(t, body, `rum_http_requests_total{method="OTHER",route="unmatched",status="4xx"} 100`+"\n")
+	assert.Contains(t, body, `rum_http_requests_total{method="GET",route="unmatched",status="4xx"} 100`+"\n")
+	assert.Contains(t, body, `rum_http_requests_total{method="OTHER",route="/cache",status="2xx"} 1`+"\n")
+	assert.NotContains(t, body, "FOO")
+}
+
+func TestMetricsHistogramBuckets(t *testing.T) {
+	f := newMetricFamily("h", "histogram", "help", []float64{1, 2, 5})
+	s := f.series("GET", "/", "2xx")
+	for _, v := range []float64{0.5, 1, 1.5, 3, 10} {
+		s.observe(v)
+	}
+	assert.Equal(t, []uint64{2, 1, 1}, s.counts)
+	assert.Equal(t, uint64(5), s.count)
+	assert.Equal(t, 16.0, s.value)
+}
+
+func TestMetricsLabelEscaping(t *testing.T) {
+	assert.Equal(t, `method="GET",route="/a\"b\\c\nd"`, formatLabels([]string{"GET", "/a\"b\\c\nd"}))
+	assert.Equal(t, "2xx", statusClass(204))
+	assert.Equal(t, "5xx", statusClass(503))
+}
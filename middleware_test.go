@@ -9,7 +9,7 @@ import (
 
 func TestMiddlewareGeneralCase(t *testing.T) {
 	signature := ""
-	router := Default()
+	router := New(":9678")
 	router.Use(func(c *Context) {
 		signature += "A"
 		c.Next()
@@ -53,3 +53,45 @@ func TestMiddlewareAbort(t *testing.T) {
 	assert.Equal(t, http.StatusOK, w.Code)
 	assert.Equal(t, "ACB", signature)
 }
+
+func TestMiddlewareNoRoute(t *testing.T) {
+	signature := ""
+	var fullPath string
+	router := New(":9678")
+	router.Use(func(c *Context) {
+		fullPath = c.FullPath()
+		signature += "A"
+		c.Next()
+		signature += "B"
+	})
+	router.GET("/users", func(c *Context) {
+		signature += "C"
+	})
+
+	// the global middleware runs before NotFound
+	w := PerformRequest(router, "GET", "/missing")
+	assert.Equal(t, http.StatusNotFound, w.Code)
+	assert.Equal(t, "404 NOT FOUND: /missing\n", w.Body.String())
+	assert.Equal(t, "AB", signature)
+	assert.Empty(t, fullPath)
+
+	// also for a path only registered for other methods
+	signature = ""
+	w = PerformRequest(router, "POST", "/users")
+	assert.Equal(t, http.StatusNotFound, w.Code)
+	assert.Equal(t, "AB", signature)
+
+	signature = ""
+	w = PerformRequest(router, "GET", "/users")
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Equal(t, "ACB", signature)
+	assert.Equal(t, "/users", fullPath)
+
+	// the middleware can answer instead of NotFound
+	router.Use(func(c *Context) {
+		c.AbortWithStatus(http.StatusTeapot)
+	})
+	w = PerformRequest(router, "GET", "/missing")
+	assert.Equal(t, http.StatusTeapot, w.Code)
+	assert.Empty(t, w.Body.String())
+}
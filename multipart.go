@@ -0,0 +1,169 @@
+package rum
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"io"
+	"mime/multipart"
+	"net/http"
+	"strings"
+)
+
+// sniffLen is the number of bytes used to detect the content type of a part.
+const sniffLen = 512
+
+var (
+	// ErrPartTooLarge is returned when a part is larger than
+	// MultipartOptions.MaxPartSize.
+	ErrPartTooLarge = errors.New("multipart: part too large")
+	// ErrTooManyParts is returned when a body has more parts than
+	// MultipartOptions.MaxParts.
+	ErrTooManyParts = errors.New("multipart: too many parts")
+	// ErrPartType is returned when the content type of a file part is not in
+	// MultipartOptions.AllowedTypes.
+	ErrPartType = errors.New("multipart: content type not allowed")
+)
+
+// MultipartOptions configures Context.StreamMultipart.
+type MultipartOptions struct {
+	// MaxPartSize is the maximum size of a part in bytes, 0 means no limit.
+	MaxPartSize int64
+
+	// MaxParts is the maximum number of parts, 0 means no limit.
+	MaxParts int
+
+	// AllowedTypes lists the content types accepted for file parts, checked
+	// against the type detected from their content. A type like "image/*"
+	// accepts every subtype. An empty list accepts every type.
+	AllowedTypes []string
+}
+
+// Part is a part of a multipart body streamed by Context.StreamMultipart.
+// Reading it fails with ErrPartTooLarge past MultipartOptions.MaxPartSize.
+type Part struct {
+	*multipart.Part
+
+	// Index is the position of the part in the body, starting at 0.
+	Index int
+
+	// ContentType is the content type detected from the first bytes of the
+	// part with http.DetectContentType, without its parameters.
+	ContentType string
+
+	r io.Reader
+}
+
+// Read reads the content of the part.
+func (p *Part) Read(b []byte) (int, error) {
+	return p.r.Read(b)
+}
+
+// IsFile reports whether the part is a file upload.
+func (p *Part) IsFile() bool {
+	return p.FileName() != ""
+}
+
+// MultipartReader returns a reader streaming the parts of a multipart/form-data
+// or multipart/mixed body, without buffering them. It can not be used once the
+// form was parsed, e.g. by FormFile, MultipartForm or a form binding.
+func (c *Context) MultipartReader() (*multipart.Reader, error) {
+	return c.Request.MultipartReader()
+}
+
+// StreamMultipart reads the multipart body part by part and passes each part
+// to fn as soon as its header is read, so that large uploads can be piped to
+// their destination without being stored. The part must be consumed within fn.
+// Streaming stops at the first error, the error returned by fn included, and
+// that error is returned.
+func (c *Context) StreamMultipart(opts MultipartOptions, fn func(*Part) error) error {
+	mr, err := c.MultipartReader()
+	if err != nil {
+		return err
+	}
+	for i := 0; ; i++ {
+		p, err := mr.NextPart()
+		if err == io.EOF {
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+		if opts.MaxParts > 0 && i >= opts.MaxParts {
+			p.Close()
+			return ErrTooManyParts
+		}
+		err = streamPart(p, i, opts, fn)
+		p.Close()
+		if err != nil {
+			return err
+		}
+	}
+}
+
+func streamPart(p *multipart.Part, index int, opts MultipartOptions, fn func(*Part) error) error {
+	var r io.Reader = p
+	if opts.MaxPartSize > 0 {
+		r = &partLimitReader{r: p, n: opts.MaxPartSize}
+	}
+	br := bufio.NewReaderSize(r, sniffLen)
+	head, err := br.Peek(sniffLen)
+	if err != nil && err != io.EOF {
+		return err
+	}
+
+	part := &Part{
+		Part:        p,
+		Index:       index,
+		ContentType: filterFlags(http.DetectContentType(head)),
+		r:           br,
+	}
+	if part.IsFile() && !typeAllowed(opts.AllowedTypes, part.ContentType) {
+		return fmt.Errorf("%w: %s (%s)", ErrPartType, part.ContentType, part.FileName())
+	}
+	return fn(part)
+}
+
+func typeAllowed(allowed []string, contentType string) bool {
+	if len(allowed) == 0 {
+		return true
+	}
+	for _, t := range allowed {
+		if t == contentType {
+			return true
+		}
+		if strings.HasSuffix(t, "/*") && strings.HasPrefix(contentType, t[:len(t)-1]) {
+			return true
+		}
+	}
+	return false
+}
+
+// partLimitReader reads at most n bytes, and fails with ErrPartTooLarge if
+// there are more.
+type partLimitReader struct {
+	r io.Reader
+	n int64
+}
+
+func (l *partLimitReader) Read(p []byte) (int, error) {
+	if l.n <= 0 {
+		// the limit is reached, fail only if the part has more data
+		var b [1]byte
+		for {
+			n, err := l.r.Read(b[:])
+			if n > 0 {
+				return 0, ErrPartTooLarge
+			}
+			if err != nil {
+				return 0, err
+			}
+		}
+	}
+	if int64(len(p)) > l.n {
+		p = p[:l.n]
+	}
+	n, err := l.r.Read(p)
+	l.n -= int64(n)
+	return n, err
+}
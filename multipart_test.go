@@ -0,0 +1,119 @@
+package rum
+
+import (
+	"bytes"
+	"io"
+	"io/ioutil"
+	"mime/multipart"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+var pngHeader = []byte("\x89PNG\x0D\x0A\x1A\x0A")
+
+func multipartContext(t *testing.T, parts func(mw *multipart.Writer)) *Context {
+	buf := new(bytes.Buffer)
+	mw := multipart.NewWriter(buf)
+	parts(mw)
+	assert.NoError(t, mw.Close())
+
+	c, _ := CreateTestContext(httptest.NewRecorder())
+	c.Request = httptest.NewRequest("POST", "/", buf)
+	c.Request.Header.Set("Content-Type", mw.FormDataContentType())
+	return c
+}
+
+func TestContextStreamMultipart(t *testing.T) {
+	c := multipartContext(t, func(mw *multipart.Writer) {
+		mw.WriteField("name", "rum")
+		w, _ := mw.CreateFormFile("image", "a.png")
+		w.Write(pngHeader)
+		w.Write(bytes.Repeat([]byte{0}, 1024))
+	})
+
+	type seen struct {
+		index       int
+		name, file  string
+		contentType string
+		size        int
+	}
+	var got []seen
+	err := c.StreamMultipart(MultipartOptions{AllowedTypes: []string{"image/*"}}, func(p *Part) error {
+		data, err := ioutil.ReadAll(p)
+		got = append(got, seen{p.Index, p.FormName(), p.FileName(), p.ContentType, len(data)})
+		return err
+	})
+	assert.NoError(t, err)
+	assert.Equal(t, []seen{
+		{0, "name", "", "text/plain", 3},
+		{1, "image", "a.png", "image/png", len(pngHeader) + 1024},
+	}, got)
+}
+
+func TestContextStreamMultipartLimits(t *testing.T) {
+	parts := func(mw *multipart.Writer) {
+		w, _ := mw.CreateFormFile("first", "a.txt")
+		w.Write(bytes.Repeat([]byte("a"), 2048))
+		w, _ = mw.CreateFormFile("second", "b.png")
+		w.Write(pngHeader)
+	}
+
+	// the limit is hit while sniffing the type
+	c := multipartContext(t, parts)
+	err := c.StreamMultipart(MultipartOptions{MaxPartSize: 100}, func(p *Part) error {
+		t.Fatal("part should not be handled")
+		return nil
+	})
+	assert.Equal(t, ErrPartTooLarge, err)
+
+	// the limit is hit while reading
+	c = multipartContext(t, parts)
+	var read int64
+	err = c.StreamMultipart(MultipartOptions{MaxPartSize: 1024}, func(p *Part) error {
+		var err error
+		read, err = io.Copy(ioutil.Discard, p)
+		return err
+	})
+	assert.Equal(t, ErrPartTooLarge, err)
+	assert.Equal(t, int64(1024), read)
+
+	c = multipartContext(t, parts)
+	err = c.StreamMultipart(MultipartOptions{MaxParts: 1}, func(p *Part) error {
+		_, err := io.Copy(ioutil.Discard, p)
+		return err
+	})
+	assert.Equal(t, ErrTooManyParts, err)
+
+	c = multipartContext(t, parts)
+	err = c.StreamMultipart(MultipartOptions{AllowedTypes: []string{"image/png"}}, func(p *Part) error {
+		return nil
+	})
+	assert.ErrorIs(t, err, ErrPartType)
+	assert.Contains(t, err.Error(), "a.txt")
+}
+
+func TestContextStreamMultipartAbort(t *testing.T) {
+	c := multipartContext(t, func(mw *multipart.Writer) {
+		mw.WriteField("a", "1")
+		mw.WriteField("b", "2")
+	})
+	stop := io.ErrUnexpectedEOF
+	calls := 0
+	err := c.StreamMultipart(MultipartOptions{}, func(p *Part) error {
+		calls++
+		return stop
+	})
+	assert.Equal(t, stop, err)
+	assert.Equal(t, 1, calls)
+}
+
+func TestContextMultipartReaderNotMultipart(t *testing.T) {
+	c, _ := CreateTestContext(httptest.NewRecorder())
+	c.Request = httptest.NewRequest("POST", "/", bytes.NewBufferString("{}"))
+	c.Request.Header.Set("Content-Type", MIMEJSON)
+	_, err := c.MultipartReader()
+	assert.Error(t, err)
+	assert.Error(t, c.StreamMultipart(MultipartOptions{}, func(*Part) error { return nil }))
+}
@@ -0,0 +1,365 @@
+package rum
+
+import (
+	"encoding/binary"
+	"hash/fnv"
+	"math"
+	"net/http"
+	"strconv"
+	"sync"
+	"time"
+)
+
+// RateLimitResult is the outcome of taking a request from a rate limit.
+type RateLimitResult struct {
+	// Allowed is true when the request is within the limit.
+	Allowed bool
+
+	// Limit is the number of requests allowed at once.
+	Limit int
+
+	// Remaining is the number of requests left.
+	Remaining int
+
+	// Reset is the time until the limit is fully restored.
+	Reset time.Duration
+
+	// RetryAfter is the time until a denied request would be allowed.
+	RetryAfter time.Duration
+}
+
+// Algorithm is a rate limiting algorithm. Its state is encoded in bytes, so
+// that a Store can keep it anywhere.
+type Algorithm interface {
+	// Take takes a request from state at now, nil for a new key, and
+	// returns the new state.
+	Take(state []byte, now time.Time) ([]byte, RateLimitResult)
+
+	// TTL is how long the state of an idle key must be kept.
+	TTL() time.Duration
+}
+
+type tokenBucket struct {
+	burst float64
+	// rate is the number of tokens added per nanosecond
+	rate float64
+}
+
+// NewTokenBucket returns a token bucket algorithm adding limit tokens per
+// period to a bucket of burst tokens, limit when burst is 0. Each request
+// takes a token.
+func NewTokenBucket(limit int, period time.Duration, burst int) Algorithm {
+	assert1(limit > 0 && period > 0, "the limit and the period must be positive")
+	if burst <= 0 {
+		burst = limit
+	}
+	return &tokenBucket{burst: float64(burst), rate: float64(limit) / float64(period)}
+}
+
+func (b *tokenBucket) Take(state []byte, now time.Time) ([]byte, RateLimitResult) {
+	tokens := b.burst
+	if len(state) == 16 {
+		tokens = math.Float64frombits(binary.BigEndian.Uint64(state))
+		last := int64(binary.BigEndian.Uint64(state[8:]))
+		if elapsed := now.UnixNano() - last; elapsed > 0 {
+			tokens = math.Min(b.burst, tokens+float64(elapsed)*b.rate)
+		}
+	}
+
+	res := RateLimitResult{Limit: int(b.burst)}
+	if tokens >= 1 {
+		tokens--
+		res.Allowed = true
+	} else {
+		res.RetryAfter = time.Duration(math.Ceil((1 - tokens) / b.rate))
+	}
+	res.Remaining = int(tokens)
+	res.Reset = time.Duration(math.Ceil((b.burst - tokens) / b.rate))
+
+	state = make([]byte, 16)
+	binary.BigEndian.PutUint64(state, math.Float64bits(tokens))
+	binary.BigEndian.PutUint64(state[8:], uint64(now.UnixNano()))
+	return state, res
+}
+
+func (b *tokenBucket) TTL() time.Duration {
+	return time.Duration(math.Ceil(b.burst / b.rate))
+}
+
+type slidingWindow struct {
+	limit  int
+	window time.Duration
+}
+
+// NewSlidingWindow returns a sliding window algorithm allowing limit
+// requests per window. The count of the sliding window is approximated by
+// weighting the count of the previous fixed window by its overlap.
+func NewSlidingWindow(limit int, window time.Duration) Algorithm {
+	assert1(limit > 0 && window > 0, "the limit and the window must be positive")
+	return &slidingWindow{limit: limit, window: window}
+}
+
+func (s *slidingWindow) Take(state []byte, now time.Time) ([]byte, RateLimitResult) {
+	window := int64(s.window)
+	start := now.UnixNano() / window * window
+	var prev, curr int64
+	if len(state) == 24 {
+		switch stored := int64(binary.BigEndian.Uint64(state)); start {
+		case stored:
+			prev = int64(binary.BigEndian.Uint64(state[8:]))
+			curr = int64(binary.BigEndian.Uint64(state[16:]))
+		case stored + window:
+			prev = int64(binary.BigEndian.Uint64(state[16:]))
+		}
+	}
+
+	elapsed := now.UnixNano() - start
+	limit := float64(s.limit)
+	count := float64(prev)*(1-float64(elapsed)/float64(window)) + float64(curr)
+	res := RateLimitResult{Limit: s.limit}
+	if count+1 <= limit {
+		curr++
+		count++
+		res.Allowed = true
+	} else if float64(curr)+1 <= limit {
+		// wait for the previous window to slide out enough
+		t := float64(window) * (1 - (limit-1-float64(curr))/float64(prev))
+		res.RetryAfter = time.Duration(math.Ceil(t)) - time.Duration(elapsed)
+	} else {
+		// wait for the next window and the current one to slide out enough
+		t := float64(window) * (1 - (limit-1)/float64(curr))
+		res.RetryAfter = time.Duration(window-elapsed) + time.Duration(math.Ceil(t))
+	}
+	res.Remaining = int(math.Max(0, limit-count))
+	switch {
+	case curr > 0:
+		res.Reset = time.Duration(2*window - elapsed)
+	case prev > 0:
+		res.Reset = time.Duration(window - elapsed)
+	}
+
+	state = make([]byte, 24)
+	binary.BigEndian.PutUint64(state, uint64(start))
+	binary.BigEndian.PutUint64(state[8:], uint64(prev))
+	binary.BigEndian.PutUint64(state[16:], uint64(curr))
+	return state, res
+}
+
+func (s *slidingWindow) TTL() time.Duration {
+	return 2 * s.window
+}
+
+// Store keeps the rate limit state of the keys. It must be safe for
+// concurrent use; a distributed store lets several servers share the limits.
+type Store interface {
+	// Update replaces the state of key, nil when missing or expired, by the
+	// state returned by fn, kept for ttl. The updates of a key must be
+	// atomic.
+	Update(key string, ttl time.Duration, fn func(state []byte) []byte) error
+}
+
+const memoryStoreShards = 64
+
+// MemoryStore is an in-memory Store. The keys are spread over shards locked
+// separately, and the expired keys are evicted as the shards grow.
+type MemoryStore struct {
+	shards [memoryStoreShards]memoryShard
+	// maxKeys is the maximum number of keys of a shard, 0 for no limit
+	maxKeys int
+	now     func() time.Time
+}
+
+type memoryShard struct {
+	mu      sync.Mutex
+	entries map[string]memoryEntry
+	// swept is the number of entries after the last sweep
+	swept int
+}
+
+type memoryEntry struct {
+	state   []byte
+	expires time.Time
+}
+
+// NewMemoryStore returns a MemoryStore keeping at most maxKeys keys, 0 for
+// no limit. When it is full, arbitrary keys are evicted, which resets their
+// limits.
+func NewMemoryStore(maxKeys int) *MemoryStore {
+	s := &MemoryStore{now: time.Now}
+	if maxKeys > 0 {
+		s.maxKeys = (maxKeys + memoryStoreShards - 1) / memoryStoreShards
+	}
+	for i := range s.shards {
+		s.shards[i].entries = make(map[string]memoryEntry)
+	}
+	return s
+}
+
+// Update implements Store.
+func (s *MemoryStore) Update(key string, ttl time.Duration, fn func(state []byte) []byte) error {
+	h := fnv.New32a()
+	h.Write([]byte(key))
+	shard := &s.shards[h.Sum32()%memoryStoreShards]
+	now := s.now()
+
+	shard.mu.Lock()
+	defer shard.mu.Unlock()
+	entry, ok := shard.entries[key]
+	if ok && !now.Before(entry.expires) {
+		entry.state = nil
+	}
+	if !ok {
+		shard.evict(now, s.maxKeys)
+	}
+	shard.entries[key] = memoryEntry{state: fn(entry.state), expires: now.Add(ttl)}
+	return nil
+}
+
+// evict makes room for a new key. The expired keys are swept when the shard
+// doubled since the last sweep, then arbitrary keys are evicted if the shard
+// is still full.
+func (s *memoryShard) evict(now time.Time, maxKeys int) {
+	full := maxKeys > 0 && len(s.entries) >= maxKeys
+	if full || len(s.entries) >= 2*s.swept && len(s.entries) >= 64 {
+		for key, entry := range s.entries {
+			if !now.Before(entry.expires) {
+				delete(s.entries, key)
+			}
+		}
+		s.swept = len(s.entries)
+	}
+	for key := range s.entries {
+		if maxKeys <= 0 || len(s.entries) < maxKeys {
+			break
+		}
+		delete(s.entries, key)
+	}
+}
+
+// Len returns the number of keys in the store, including the expired ones
+// not evicted yet.
+func (s *MemoryStore) Len() int {
+	n := 0
+	for i := range s.shards {
+		s.shards[i].mu.Lock()
+		n += len(s.shards[i].entries)
+		s.shards[i].mu.Unlock()
+	}
+	return n
+}
+
+// KeyFunc returns the key a request is limited by.
+type KeyFunc func(c *Context) string
+
+// KeyByClientIP limits the requests by the IP address of the client, see
+// Context.ClientIP.
+func KeyByClientIP(c *Context) string {
+	return c.ClientIP()
+}
+
+// KeyByHeader limits the requests by the value of a header, or by the IP
+// address of the client when the header is missing.
+func KeyByHeader(name string) KeyFunc {
+	return func(c *Context) string {
+		if v := c.requestHeader(name); v != "" {
+			return name + ":" + v
+		}
+		return KeyByClientIP(c)
+	}
+}
+
+// KeyByAPIKey limits the requests by the string stored in Context.Keys
+// under key, e.g. by an authentication middleware, or by the IP address of
+// the client when it is missing.
+func KeyByAPIKey(key string) KeyFunc {
+	return func(c *Context) string {
+		if v, ok := c.Get(key); ok {
+			if s, ok := v.(string); ok && s != "" {
+				return key + ":" + s
+			}
+		}
+		return KeyByClientIP(c)
+	}
+}
+
+// RateLimitConfig configures the RateLimit middleware.
+type RateLimitConfig struct {
+	// Algorithm limits the requests of each key, see NewTokenBucket and
+	// NewSlidingWindow.
+	Algorithm Algorithm
+
+	// Key returns the key the requests are limited by, KeyByClientIP when
+	// nil.
+	Key KeyFunc
+
+	// Store keeps the state of the keys, a new MemoryStore without limit
+	// when nil.
+	Store Store
+
+	// PerRoute limits the requests of each route separately, instead of
+	// the requests of all the routes using the middleware together.
+	PerRoute bool
+
+	// LimitReached, when set, writes the response of the denied requests,
+	// which are answered with 429 otherwise.
+	LimitReached HandlerFunc
+}
+
+// RateLimit returns a middleware limiting the rate of the requests. It sets
+// the RateLimit-Limit, RateLimit-Remaining and RateLimit-Reset headers,
+// the latter in seconds, and aborts the denied requests with 429 and a
+// Retry-After header. When the store fails, the error is added to
+// Context.Errors and the request is allowed.
+func RateLimit(cfg RateLimitConfig) HandlerFunc {
+	assert1(cfg.Algorithm != nil, "a rate limiting algorithm is required")
+	if cfg.Key == nil {
+		cfg.Key = KeyByClientIP
+	}
+	if cfg.Store == nil {
+		cfg.Store = NewMemoryStore(0)
+	}
+	ttl := cfg.Algorithm.TTL()
+
+	return func(c *Context) {
+		key := cfg.Key(c)
+		if cfg.PerRoute {
+			key = c.Method + " " + c.FullPath() + " " + key
+		}
+		var res RateLimitResult
+		err := cfg.Store.Update(key, ttl, func(state []byte) []byte {
+			state, res = cfg.Algorithm.Take(state, time.Now())
+			return state
+		})
+		if err != nil {
+			c.Error(err)
+			c.Next()
+			return
+		}
+
+		h := c.Writer.Header()
+		h.Set("RateLimit-Limit", strconv.Itoa(res.Limit))
+		h.Set("RateLimit-Remaining", strconv.Itoa(res.Remaining))
+		h.Set("RateLimit-Reset", strconv.FormatInt(seconds(res.Reset), 10))
+		if res.Allowed {
+			c.Next()
+			return
+		}
+
+		retry := seconds(res.RetryAfter)
+		if retry < 1 {
+			retry = 1
+		}
+		h.Set("Retry-After", strconv.FormatInt(retry, 10))
+		if cfg.LimitReached != nil {
+			cfg.LimitReached(c)
+			c.Abort()
+			return
+		}
+		c.AbortWithStatus(http.StatusTooManyRequests)
+	}
+}
+
+// seconds rounds d up to seconds.
+func seconds(d time.Duration) int64 {
+	return int64((d + time.Second - 1) / time.Second)
+}
@@ -0,0 +1,219 @@
+package rum
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestTokenBucket(t *testing.T) {
+	// 1 token per second, up to 3 at once
+	tb := NewTokenBucket(1, time.Second, 3)
+	assert.Equal(t, 3*time.Second, tb.TTL())
+	now := time.Unix(1000, 0)
+
+	var state []byte
+	var res RateLimitResult
+	for i := 2; i >= 0; i-- {
+		state, res = tb.Take(state, now)
+		assert.True(t, res.Allowed)
+		assert.Equal(t, 3, res.Limit)
+		assert.Equal(t, i, res.Remaining)
+	}
+	assert.Equal(t, 3*time.Second, res.Reset)
+
+	state, res = tb.Take(state, now.Add(500*time.Millisecond))
+	assert.False(t, res.Allowed)
+	assert.Equal(t, 0, res.Remaining)
+	assert.Equal(t, 500*time.Millisecond, res.RetryAfter)
+
+	state, res = tb.Take(state, now.Add(time.Second))
+	assert.True(t, res.Allowed)
+	assert.Equal(t, 0, res.Remaining)
+
+	// the bucket does not overflow
+	_, res = tb.Take(state, now.Add(time.Hour))
+	assert.True(t, res.Allowed)
+	assert.Equal(t, 2, res.Remaining)
+	assert.Equal(t, time.Second, res.Reset)
+
+	assert.Panics(t, func() { NewTokenBucket(0, time.Second, 1) })
+}
+
+func TestSlidingWindow(t *testing.T) {
+	sw := NewSlidingWindow(4, 10*time.Second)
+	assert.Equal(t, 20*time.Second, sw.TTL())
+	start := time.Unix(1000, 0)
+
+	var state []byte
+	var res RateLimitResult
+	for i := 3; i >= 0; i-- {
+		state, res = sw.Take(state, start.Add(time.Second))
+		assert.True(t, res.Allowed)
+		assert.Equal(t, 4, res.Limit)
+		assert.Equal(t, i, res.Remaining)
+	}
+	assert.Equal(t, 19*time.Second, res.Reset)
+
+	// 4 requests in the current window: wait for the next window, and for
+	// the previous one to slide out by 1/4
+	state, res = sw.Take(state, start.Add(5*time.Second))
+	assert.False(t, res.Allowed)
+	assert.Equal(t, 5*time.Second+2500*time.Millisecond, res.RetryAfter)
+
+	state, res = sw.Take(state, start.Add(12*time.Second))
+	assert.False(t, res.Allowed)
+	assert.Equal(t, 500*time.Millisecond, res.RetryAfter)
+
+	// the previous window counts for 3/4 at 12.5s, then for 1/2 at 15s
+	state, res = sw.Take(state, start.Add(12500*time.Millisecond))
+	assert.True(t, res.Allowed)
+	assert.Equal(t, 0, res.Remaining)
+	state, res = sw.Take(state, start.Add(15*time.Second))
+	assert.True(t, res.Allowed)
+	assert.Equal(t, 0, res.Remaining)
+	_, res = sw.Take(state, start.Add(15*time.Second))
+	assert.False(t, res.Allowed)
+	assert.Equal(t, 2500*time.Millisecond, res.RetryAfter)
+
+	// the state of an older window is dropped
+	_, res = sw.Take(state, start.Add(time.Minute))
+	assert.True(t, res.Allowed)
+	assert.Equal(t, 3, res.Remaining)
+}
+
+func TestMemoryStore(t *testing.T) {
+	now := time.Unix(1000, 0)
+	s := NewMemoryStore(0)
+	s.now = func() time.Time { return now }
+	incr := func(state []byte) []byte {
+		if state == nil {
+			return []byte{1}
+		}
+		return []byte{state[0] + 1}
+	}
+	get := func(key string) byte {
+		var v byte
+		s.Update(key, time.Second, func(state []byte) []byte {
+			if state != nil {
+				v = state[0]
+			}
+			return state
+		})
+		return v
+	}
+
+	s.Update("a", time.Second, incr)
+	s.Update("a", time.Second, incr)
+	assert.Equal(t, byte(2), get("a"))
+	now = now.Add(time.Second)
+	assert.Equal(t, byte(0), get("a"))
+
+	// the expired keys are swept as the shards grow
+	for i := 0; i < 1000; i++ {
+		s.Update(strconv.Itoa(i), time.Second, incr)
+	}
+	now = now.Add(time.Second)
+	for i := 1000; i < 10000; i++ {
+		s.Update(strconv.Itoa(i), time.Second, incr)
+	}
+	assert.Less(t, s.Len(), 10000)
+
+	s = NewMemoryStore(640)
+	s.now = func() time.Time { return now }
+	for i := 0; i < 10000; i++ {
+		s.Update(strconv.Itoa(i), time.Hour, incr)
+	}
+	assert.LessOrEqual(t, s.Len(), 640)
+}
+
+type failingStore struct{}
+
+func (failingStore) Update(string, time.Duration, func([]byte) []byte) error {
+	return errors.New("store unavailable")
+}
+
+func TestRateLimit(t *testing.T) {
+	router := New(":9678")
+	router.Use(RateLimit(RateLimitConfig{Algorithm: NewTokenBucket(2, time.Hour, 0)}))
+	router.GET("/", func(c *Context) {
+		c.String(http.StatusOK, "ok")
+	})
+
+	for i := 1; i >= 0; i-- {
+		w := PerformRequest(router, "GET", "/", withRemoteAddr("10.0.0.1:1234"))
+		assert.Equal(t, http.StatusOK, w.Code)
+		assert.Equal(t, "2", w.Header().Get("RateLimit-Limit"))
+		assert.Equal(t, strconv.Itoa(i), w.Header().Get("RateLimit-Remaining"))
+		assert.Empty(t, w.Header().Get("Retry-After"))
+	}
+	w := PerformRequest(router, "GET", "/", withRemoteAddr("10.0.0.1:4321"))
+	assert.Equal(t, http.StatusTooManyRequests, w.Code)
+	assert.Empty(t, w.Body.String())
+	assert.Equal(t, "0", w.Header().Get("RateLimit-Remaining"))
+	assert.Equal(t, "3600", w.Header().Get("RateLimit-Reset"))
+	assert.Equal(t, "1800", w.Header().Get("Retry-After"))
+
+	// another client has its own limit
+	w = PerformRequest(router, "GET", "/", withRemoteAddr("10.0.0.2:1234"))
+	assert.Equal(t, http.StatusOK, w.Code)
+}
+
+func TestRateLimitKeys(t *testing.T) {
+	router := New(":9678")
+	limited := func(key KeyFunc) HandlerFunc {
+		return RateLimit(RateLimitConfig{
+			Algorithm:    NewSlidingWindow(1, time.Hour),
+			Key:          key,
+			PerRoute:     true,
+			LimitReached: func(c *Context) { c.String(http.StatusTooManyRequests, "slow down") },
+		})
+	}
+	auth := func(c *Context) {
+		if token := c.requestHeader("Authorization"); token != "" {
+			c.Set("api_key", token)
+		}
+	}
+	ok := func(c *Context) {}
+	router.GET("/header", limited(KeyByHeader("X-Client")), ok)
+	router.GET("/api/a", auth, limited(KeyByAPIKey("api_key")), ok)
+	router.GET("/api/b", auth, limited(KeyByAPIKey("api_key")), ok)
+
+	assert.Equal(t, http.StatusOK, PerformRequest(router, "GET", "/header", withRemoteAddr("10.0.0.1:1"), header{"X-Client", "a"}).Code)
+	assert.Equal(t, http.StatusOK, PerformRequest(router, "GET", "/header", withRemoteAddr("10.0.0.1:1"), header{"X-Client", "b"}).Code)
+	assert.Equal(t, http.StatusOK, PerformRequest(router, "GET", "/header", withRemoteAddr("10.0.0.1:1")).Code)
+	w := PerformRequest(router, "GET", "/header", withRemoteAddr("10.0.0.2:1"), header{"X-Client", "a"})
+	assert.Equal(t, http.StatusTooManyRequests, w.Code)
+	assert.Equal(t, "slow down", w.Body.String())
+	assert.Equal(t, http.StatusTooManyRequests, PerformRequest(router, "GET", "/header", withRemoteAddr("10.0.0.1:2")).Code)
+
+	for _, path := range []string{"/api/a", "/api/b"} {
+		assert.Equal(t, http.StatusOK, PerformRequest(router, "GET", path, withRemoteAddr("10.0.0.1:1"), header{"Authorization", "k1"}).Code)
+		assert.Equal(t, http.StatusTooManyRequests, PerformRequest(router, "GET", path, withRemoteAddr("10.0.0.2:1"), header{"Authorization", "k1"}).Code)
+		assert.Equal(t, http.StatusOK, PerformRequest(router, "GET", path, withRemoteAddr("10.0.0.1:1"), header{"Authorization", "k2"}).Code)
+	}
+}
+
+func TestRateLimitStoreError(t *testing.T) {
+	router := New(":9678")
+	var errs []*Error
+	router.Use(func(c *Context) {
+		c.Next()
+		errs = append([]*Error(nil), c.Errors...)
+	})
+	router.Use(RateLimit(RateLimitConfig{Algorithm: NewTokenBucket(1, time.Hour, 0), Store: failingStore{}}))
+	router.GET("/", func(c *Context) {})
+
+	for i := 0; i < 2; i++ {
+		w := PerformRequest(router, "GET", "/", withRemoteAddr("10.0.0.1:1"))
+		assert.Equal(t, http.StatusOK, w.Code)
+		assert.Empty(t, w.Header().Get("RateLimit-Limit"))
+		if assert.Len(t, errs, 1) {
+			assert.EqualError(t, errs[0].Err, "store unavailable")
+		}
+	}
+}
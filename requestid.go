@@ -0,0 +1,129 @@
+package rum
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"time"
+)
+
+// RequestIDKey is the key of the request ID in Context.Keys, see RequestID.
+const RequestIDKey = "rum/request_id"
+
+// RequestIDHeader is the default header carrying the request ID.
+const RequestIDHeader = "X-Request-ID"
+
+// maxRequestIDLen is the length above which an incoming request ID is
+// replaced by a generated one.
+const maxRequestIDLen = 128
+
+// RequestIDConfig configures the RequestID middleware.
+type RequestIDConfig struct {
+	// Header is the header the request ID is read from and echoed in,
+	// RequestIDHeader when empty.
+	Header string
+
+	// Generator generates the ID of the requests without one, NewUUID when
+	// nil. NewULID generates sortable IDs.
+	Generator func() string
+}
+
+// RequestID returns a middleware giving every request an ID, read from the
+// request header or generated when the header is missing or invalid. The ID
+// is stored in Context.Keys under RequestIDKey, returned by
+// Context.RequestID and sent in the same header of the response.
+func RequestID(cfg RequestIDConfig) HandlerFunc {
+	if cfg.Header == "" {
+		cfg.Header = RequestIDHeader
+	}
+	if cfg.Generator == nil {
+		cfg.Generator = NewUUID
+	}
+	return func(c *Context) {
+		id := c.requestHeader(cfg.Header)
+		if !validRequestID(id) {
+			id = cfg.Generator()
+		}
+		c.Set(RequestIDKey, id)
+		c.SetHeader(cfg.Header, id)
+		c.Next()
+	}
+}
+
+// RequestID returns the ID of the request set by the RequestID middleware,
+// or "".
+func (c *Context) RequestID() string {
+	if v, ok := c.Get(RequestIDKey); ok {
+		id, _ := v.(string)
+		return id
+	}
+	return ""
+}
+
+// validRequestID reports whether id is short and only made of visible ASCII
+// characters, so that it can be safely echoed and logged.
+func validRequestID(id string) bool {
+	if id == "" || len(id) > maxRequestIDLen {
+		return false
+	}
+	for i := 0; i < len(id); i++ {
+		if id[i] <= ' ' || id[i] > '~' {
+			return false
+		}
+	}
+	return true
+}
+
+// NewUUID returns a random (version 4) UUID, e.g.
+// "f47ac10b-58cc-4372-a567-0e02b2c3d479".
+func NewUUID() string {
+	var u [16]byte
+	rand.Read(u[:])
+	u[6] = u[6]&0x0f | 0x40 // version 4
+	u[8] = u[8]&0x3f | 0x80 // variant 10
+
+	var buf [36]byte
+	hex.Encode(buf[0:8], u[0:4])
+	buf[8] = '-'
+	hex.Encode(buf[9:13], u[4:6])
+	buf[13] = '-'
+	hex.Encode(buf[14:18], u[6:8])
+	buf[18] = '-'
+	hex.Encode(buf[19:23], u[8:10])
+	buf[23] = '-'
+	hex.Encode(buf[24:], u[10:])
+	return string(buf[:])
+}
+
+// crockford is the base32 alphabet of ULIDs.
+const crockford = "0123456789ABCDEFGHJKMNPQRSTVWXYZ"
+
+// NewULID returns a ULID, https://github.com/ulid/spec: a 48-bit millisecond
+// timestamp followed by 80 random bits, encoded in 26 characters which sort
+// in the order the IDs were generated (within a millisecond, in random
+// order).
+func NewULID() string {
+	return newULID(time.Now())
+}
+
+func newULID(t time.Time) string {
+	var u [16]byte
+	ms := uint64(t.UnixNano() / int64(time.Millisecond))
+	for i := 5; i >= 0; i-- {
+		u[i] = byte(ms)
+		ms >>= 8
+	}
+	rand.Read(u[6:])
+
+	// 128 bits in 26 characters of 5 bits, the first one holds 3 bits
+	var buf [26]byte
+	hi := uint64(u[0])<<56 | uint64(u[1])<<48 | uint64(u[2])<<40 | uint64(u[3])<<32 |
+		uint64(u[4])<<24 | uint64(u[5])<<16 | uint64(u[6])<<8 | uint64(u[7])
+	lo := uint64(u[8])<<56 | uint64(u[9])<<48 | uint64(u[10])<<40 | uint64(u[11])<<32 |
+		uint64(u[12])<<24 | uint64(u[13])<<16 | uint64(u[14])<<8 | uint64(u[15])
+	for i := 25; i >= 0; i-- {
+		buf[i] = crockford[lo&0x1f]
+		lo = lo>>5 | hi<<59
+		hi >>= 5
+	}
+	return string(buf[:])
+}
@@ -0,0 +1,76 @@
+package rum
+
+import (
+	"net/http/httptest"
+	"regexp"
+	"sort"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRequestID(t *testing.T) {
+	router := New(":9678")
+	router.Use(RequestID(RequestIDConfig{}))
+	var id string
+	router.GET("/", func(c *Context) {
+		id = c.RequestID()
+	})
+
+	w := PerformRequest(router, "GET", "/", header{"X-Request-ID", "abc-123"})
+	assert.Equal(t, "abc-123", id)
+	assert.Equal(t, "abc-123", w.Header().Get("X-Request-ID"))
+
+	for _, incoming := range []string{"", "has space", "new\nline", strings.Repeat("a", 129)} {
+		w = PerformRequest(router, "GET", "/", header{"X-Request-ID", incoming})
+		assert.Regexp(t, `^[0-9a-f]{8}-[0-9a-f]{4}-4[0-9a-f]{3}-[89ab][0-9a-f]{3}-[0-9a-f]{12}$`, id)
+		assert.Equal(t, id, w.Header().Get("X-Request-ID"))
+	}
+}
+
+func TestRequestIDConfig(t *testing.T) {
+	router := New(":9678")
+	router.Use(RequestID(RequestIDConfig{Header: "X-Correlation-ID", Generator: func() string { return "generated" }}))
+	router.GET("/", func(c *Context) {})
+
+	w := PerformRequest(router, "GET", "/", header{"X-Request-ID", "ignored"})
+	assert.Equal(t, "generated", w.Header().Get("X-Correlation-ID"))
+	assert.Empty(t, w.Header().Get("X-Request-ID"))
+
+	w = PerformRequest(router, "GET", "/", header{"X-Correlation-ID", "kept"})
+	assert.Equal(t, "kept", w.Header().Get("X-Correlation-ID"))
+}
+
+func TestRequestIDTracing(t *testing.T) {
+	exporter := &InMemoryExporter{}
+	router := New(":9678")
+	router.Use(Tracing(TracingConfig{Exporter: exporter}), RequestID(RequestIDConfig{}))
+	router.GET("/", func(c *Context) {})
+
+	PerformRequest(router, "GET", "/", header{"X-Request-ID", "abc"})
+	if spans := exporter.Spans(); assert.Len(t, spans, 1) {
+		assert.Equal(t, "abc", spans[0].Attributes["http.request_id"])
+	}
+}
+
+func TestContextRequestIDWithoutMiddleware(t *testing.T) {
+	c, _ := CreateTestContext(httptest.NewRecorder())
+	assert.Empty(t, c.RequestID())
+}
+
+func TestNewULID(t *testing.T) {
+	// the timestamp of the example of the spec
+	id := newULID(time.Unix(0, 1469918176385*int64(time.Millisecond)))
+	assert.Regexp(t, `^01ARYZ6S41[0-9A-HJKMNP-TV-Z]{16}$`, id)
+
+	ids := make([]string, 0, 3)
+	for i := 0; i < 3; i++ {
+		ids = append(ids, NewULID())
+		time.Sleep(2 * time.Millisecond)
+	}
+	assert.True(t, sort.StringsAreSorted(ids))
+	assert.NotEqual(t, NewUUID(), NewUUID())
+	assert.True(t, regexp.MustCompile(`^[0-7]`).MatchString(NewULID()))
+}
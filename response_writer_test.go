@@ -0,0 +1,112 @@
+// Copyright 2014 Manu Martinez-Almeida.  All rights reserved.
+// Use of this source code is governed by a MIT style
+// license that can be found in the LICENSE file.
+
+package rum
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+var (
+	_ ResponseWriter = &responseWriter{}
+	_ http.Flusher   = &responseWriter{}
+	_ http.Hijacker  = &responseWriter{}
+)
+
+func TestResponseWriterReset(t *testing.T) {
+	testWriter := httptest.NewRecorder()
+	writer := &responseWriter{}
+	var w ResponseWriter = writer
+
+	writer.reset(testWriter)
+	assert.Equal(t, -1, writer.size)
+	assert.Equal(t, http.StatusOK, writer.status)
+	assert.Equal(t, testWriter, writer.ResponseWriter)
+	assert.Equal(t, -1, w.Size())
+	assert.Equal(t, http.StatusOK, w.Status())
+	assert.False(t, w.Written())
+}
+
+func TestResponseWriterWriteHeader(t *testing.T) {
+	testWriter := httptest.NewRecorder()
+	writer := &responseWriter{}
+	writer.reset(testWriter)
+	w := ResponseWriter(writer)
+
+	w.WriteHeader(http.StatusMultipleChoices)
+	assert.False(t, w.Written())
+	assert.Equal(t, http.StatusMultipleChoices, w.Status())
+	assert.NotEqual(t, http.StatusMultipleChoices, testWriter.Code)
+
+	w.WriteHeader(-1)
+	assert.Equal(t, http.StatusMultipleChoices, w.Status())
+}
+
+func TestResponseWriterWriteHeadersNow(t *testing.T) {
+	testWriter := httptest.NewRecorder()
+	writer := &responseWriter{}
+	writer.reset(testWriter)
+	w := ResponseWriter(writer)
+
+	w.WriteHeader(http.StatusMultipleChoices)
+	w.WriteHeaderNow()
+
+	assert.True(t, w.Written())
+	assert.Equal(t, 0, w.Size())
+	assert.Equal(t, http.StatusMultipleChoices, testWriter.Code)
+
+	// the status can not change once the header is sent
+	w.WriteHeader(http.StatusNotFound)
+	assert.Equal(t, http.StatusMultipleChoices, w.Status())
+}
+
+func TestResponseWriterWrite(t *testing.T) {
+	testWriter := httptest.NewRecorder()
+	writer := &responseWriter{}
+	writer.reset(testWriter)
+	w := ResponseWriter(writer)
+
+	n, err := w.Write([]byte("hola"))
+	assert.Equal(t, 4, n)
+	assert.Equal(t, 4, w.Size())
+	assert.Equal(t, http.StatusOK, w.Status())
+	assert.Equal(t, http.StatusOK, testWriter.Code)
+	assert.Equal(t, "hola", testWriter.Body.String())
+	assert.NoError(t, err)
+
+	n, err = w.WriteString(" adios")
+	assert.Equal(t, 6, n)
+	assert.Equal(t, 10, w.Size())
+	assert.Equal(t, "hola adios", testWriter.Body.String())
+	assert.NoError(t, err)
+}
+
+func TestResponseWriterHijack(t *testing.T) {
+	testWriter := httptest.NewRecorder()
+	writer := &responseWriter{}
+	writer.reset(testWriter)
+	w := ResponseWriter(writer)
+
+	_, _, err := w.Hijack()
+	assert.Error(t, err)
+	assert.False(t, w.Written())
+}
+
+func TestResponseWriterFlush(t *testing.T) {
+	testWriter := httptest.NewRecorder()
+	writer := &responseWriter{}
+	writer.reset(testWriter)
+	w := ResponseWriter(writer)
+
+	w.WriteHeader(http.StatusAccepted)
+	w.Flush()
+
+	assert.True(t, testWriter.Flushed)
+	assert.Equal(t, http.StatusAccepted, testWriter.Code)
+	assert.Equal(t, testWriter, w.Unwrap())
+}
@@ -31,6 +31,7 @@ type RouterGroup struct {
 
 func (group *RouterGroup) combine(handlers HandlersChain) HandlersChain {
 	finalSize := len(group.Handlers) + len(handlers)
+	assert1(finalSize < abortInx, "too many handlers")
 	mergedHandlers := make(HandlersChain, finalSize)
 	copy(mergedHandlers, group.Handlers)
 	copy(mergedHandlers[len(group.Handlers):], handlers)
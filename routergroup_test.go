@@ -22,3 +22,24 @@ func TestRouterGroupBasic(t *testing.T) {
 	assert.Equal(t, "/hola/manu", group2.BasePath)
 	assert.Equal(t, router, group2.engine)
 }
+
+func TestRouterGroupTooManyHandlers(t *testing.T) {
+	router := New(":9678")
+	handlers := make([]HandlerFunc, abortInx)
+	for i := range handlers {
+		handlers[i] = func(c *Context) {}
+	}
+
+	assert.Panics(t, func() {
+		router.GET("/", handlers...)
+	})
+	assert.Panics(t, func() {
+		router.Group("/", handlers...)
+	})
+	// the global middleware is also combined with NotFound
+	assert.Panics(t, func() {
+		router.Use(handlers[1:]...)
+	})
+	assert.Empty(t, router.group.Handlers)
+	assert.Len(t, router.allNoRoute, 1)
+}
@@ -1,6 +1,8 @@
 package rum
 
 import (
+	"bytes"
+	"io/ioutil"
 	"net/http"
 	"net/http/httptest"
 	"testing"
@@ -18,11 +20,43 @@ func TestCreateEngine(t *testing.T) {
 	assert.Equal(t, ":9678", router.addr)
 }
 
+// requestOption is a header, or any change, applied to the request of
+// PerformRequest.
+type requestOption interface {
+	apply(req *http.Request)
+}
+
+func (h header) apply(req *http.Request) {
+	req.Header.Add(h.Key, h.Value)
+}
+
+// withRequest changes the request of PerformRequest.
+type withRequest func(req *http.Request)
+
+func (f withRequest) apply(req *http.Request) {
+	f(req)
+}
+
+// withBody sends body with an unknown length, like a chunked request.
+func withBody(body []byte) withRequest {
+	return func(req *http.Request) {
+		req.Body = ioutil.NopCloser(bytes.NewReader(body))
+		req.ContentLength = -1
+	}
+}
+
+// withRemoteAddr sends the request from addr.
+func withRemoteAddr(addr string) withRequest {
+	return func(req *http.Request) {
+		req.RemoteAddr = addr
+	}
+}
+
 // PerformRequest for testing router.
-func PerformRequest(r http.Handler, method, path string, headers ...header) *httptest.ResponseRecorder {
+func PerformRequest(r http.Handler, method, path string, options ...requestOption) *httptest.ResponseRecorder {
 	req := httptest.NewRequest(method, path, nil)
-	for _, h := range headers {
-		req.Header.Add(h.Key, h.Value)
+	for _, option := range options {
+		option.apply(req)
 	}
 	w := httptest.NewRecorder()
 	r.ServeHTTP(w, req)
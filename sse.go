@@ -0,0 +1,80 @@
+package rum
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+)
+
+// Event is a single Server-Sent Event as described by the WHATWG
+// "server-sent events" specification. Empty fields are omitted from the frame.
+type Event struct {
+	Event string
+	Id    string
+	Retry uint
+	Data  interface{}
+}
+
+// ErrInvalidEventField is returned when the id or the name of an event
+// contains a CR, a LF or a NUL, which the specification does not allow.
+var ErrInvalidEventField = errors.New("sse: id or event contains CR, LF or NUL")
+
+var lineReplacer = strings.NewReplacer("\r\n", "\n", "\r", "\n")
+
+// Encode writes the event to w as a single frame terminated by a blank line.
+// Data that spans several lines is sent as several "data:" fields, which the
+// client joins back with newlines. The id and the name of the event are sent
+// as is and must not contain a CR, a LF or a NUL.
+func (e Event) Encode(w io.Writer) error {
+	if strings.ContainsAny(e.Id, "\r\n\x00") || strings.ContainsAny(e.Event, "\r\n\x00") {
+		return ErrInvalidEventField
+	}
+	var b strings.Builder
+	if e.Id != "" {
+		b.WriteString("id: ")
+		b.WriteString(e.Id)
+		b.WriteByte('\n')
+	}
+	if e.Event != "" {
+		b.WriteString("event: ")
+		b.WriteString(e.Event)
+		b.WriteByte('\n')
+	}
+	if e.Retry > 0 {
+		fmt.Fprintf(&b, "retry: %d\n", e.Retry)
+	}
+
+	data, err := eventData(e.Data)
+	if err != nil {
+		return err
+	}
+	for _, line := range strings.Split(lineReplacer.Replace(data), "\n") {
+		b.WriteString("data: ")
+		b.WriteString(line)
+		b.WriteByte('\n')
+	}
+	b.WriteByte('\n')
+
+	_, err = io.WriteString(w, b.String())
+	return err
+}
+
+func eventData(data interface{}) (string, error) {
+	switch v := data.(type) {
+	case nil:
+		return "", nil
+	case string:
+		return v, nil
+	case []byte:
+		return string(v), nil
+	case fmt.Stringer:
+		return v.String(), nil
+	}
+	b, err := json.Marshal(data)
+	if err != nil {
+		return "", err
+	}
+	return string(b), nil
+}
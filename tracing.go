@@ -0,0 +1,333 @@
+package rum
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"net/http"
+	"strconv"
+	"sync"
+	"time"
+)
+
+// SpanKey is the key of the request span in Context.Keys, see Tracing.
+const SpanKey = "rum/span"
+
+// The W3C Trace Context headers.
+const (
+	TraceparentHeader = "traceparent"
+	TracestateHeader  = "tracestate"
+)
+
+// maxTracestateLen is the length above which a tracestate may be discarded.
+const maxTracestateLen = 512
+
+// TraceID identifies a trace.
+type TraceID [16]byte
+
+// IsValid reports whether the trace ID is not all zeros.
+func (id TraceID) IsValid() bool {
+	return id != TraceID{}
+}
+
+func (id TraceID) String() string {
+	return hex.EncodeToString(id[:])
+}
+
+// SpanID identifies a span within a trace.
+type SpanID [8]byte
+
+// IsValid reports whether the span ID is not all zeros.
+func (id SpanID) IsValid() bool {
+	return id != SpanID{}
+}
+
+func (id SpanID) String() string {
+	return hex.EncodeToString(id[:])
+}
+
+// FlagSampled is the trace flag telling the trace is recorded.
+const FlagSampled byte = 0x01
+
+// SpanContext is the part of a span propagated across services.
+type SpanContext struct {
+	TraceID    TraceID
+	SpanID     SpanID
+	Flags      byte
+	TraceState string
+	// Remote is true when the span context was received from another service.
+	Remote bool
+}
+
+// IsValid reports whether both the trace and the span IDs are valid.
+func (sc SpanContext) IsValid() bool {
+	return sc.TraceID.IsValid() && sc.SpanID.IsValid()
+}
+
+// IsSampled reports whether the sampled flag is set.
+func (sc SpanContext) IsSampled() bool {
+	return sc.Flags&FlagSampled != 0
+}
+
+// Traceparent formats sc as the value of a traceparent header.
+func (sc SpanContext) Traceparent() string {
+	return "00-" + sc.TraceID.String() + "-" + sc.SpanID.String() + "-" + hex.EncodeToString([]byte{sc.Flags})
+}
+
+// ParseTraceparent parses the value of a traceparent header, as defined by
+// https://www.w3.org/TR/trace-context/#traceparent-header. The returned span
+// context is remote.
+func ParseTraceparent(s string) (SpanContext, bool) {
+	var sc SpanContext
+	// version-traceid-parentid-flags, later versions may append fields
+	if len(s) < 55 || s[2] != '-' || s[35] != '-' || s[52] != '-' {
+		return sc, false
+	}
+	version, ok := decodeHex(s[:2], 1)
+	if !ok || version[0] == 0xff || version[0] == 0 && len(s) != 55 || len(s) > 55 && s[55] != '-' {
+		return sc, false
+	}
+	traceID, ok := decodeHex(s[3:35], 16)
+	if !ok {
+		return sc, false
+	}
+	spanID, ok := decodeHex(s[36:52], 8)
+	if !ok {
+		return sc, false
+	}
+	flags, ok := decodeHex(s[53:55], 1)
+	if !ok {
+		return sc, false
+	}
+	copy(sc.TraceID[:], traceID)
+	copy(sc.SpanID[:], spanID)
+	sc.Flags = flags[0]
+	sc.Remote = true
+	return sc, sc.IsValid()
+}
+
+// decodeHex decodes n bytes of lowercase hexadecimal.
+func decodeHex(s string, n int) ([]byte, bool) {
+	if len(s) != 2*n {
+		return nil, false
+	}
+	for i := 0; i < len(s); i++ {
+		if c := s[i]; !('0' <= c && c <= '9' || 'a' <= c && c <= 'f') {
+			return nil, false
+		}
+	}
+	b, err := hex.DecodeString(s)
+	return b, err == nil
+}
+
+// SpanStatus is the status of a span.
+type SpanStatus int
+
+// The statuses of a span.
+const (
+	SpanStatusUnset SpanStatus = iota
+	SpanStatusOK
+	SpanStatusError
+)
+
+// SpanExporter receives the spans once they end. It must be safe for
+// concurrent use.
+type SpanExporter interface {
+	ExportSpan(span *Span)
+}
+
+// Span is a timed operation of a trace, such as the handling of a request.
+// Its fields must not be modified once it ended.
+type Span struct {
+	Name        string
+	SpanContext SpanContext
+	// Parent is the context of the parent span, invalid for a root span.
+	Parent     SpanContext
+	Start, End time.Time
+
+	mu            sync.Mutex
+	Attributes    map[string]interface{}
+	Errors        []error
+	Status        SpanStatus
+	StatusMessage string
+
+	exporter SpanExporter
+	ended    bool
+}
+
+func newSpan(name string, parent SpanContext, exporter SpanExporter) *Span {
+	sc := SpanContext{Flags: FlagSampled}
+	if parent.IsValid() {
+		sc.TraceID, sc.Flags, sc.TraceState = parent.TraceID, parent.Flags, parent.TraceState
+	} else {
+		rand.Read(sc.TraceID[:])
+	}
+	rand.Read(sc.SpanID[:])
+	return &Span{
+		Name:        name,
+		SpanContext: sc,
+		Parent:      parent,
+		Start:       time.Now(),
+		Attributes:  make(map[string]interface{}),
+		exporter:    exporter,
+	}
+}
+
+// StartChild starts a span whose parent is s, exported like s once it ends.
+func (s *Span) StartChild(name string) *Span {
+	return newSpan(name, s.SpanContext, s.exporter)
+}
+
+// SetAttribute sets an attribute of the span.
+func (s *Span) SetAttribute(key string, value interface{}) {
+	s.mu.Lock()
+	s.Attributes[key] = value
+	s.mu.Unlock()
+}
+
+// RecordError records err and sets the status of the span to error.
+func (s *Span) RecordError(err error) {
+	s.mu.Lock()
+	s.Errors = append(s.Errors, err)
+	s.Status, s.StatusMessage = SpanStatusError, err.Error()
+	s.mu.Unlock()
+}
+
+// SetStatus sets the status of the span.
+func (s *Span) SetStatus(status SpanStatus, message string) {
+	s.mu.Lock()
+	s.Status, s.StatusMessage = status, message
+	s.mu.Unlock()
+}
+
+// Inject sets the traceparent and tracestate headers of an outgoing request,
+// so that the called service continues the trace.
+func (s *Span) Inject(header http.Header) {
+	header.Set(TraceparentHeader, s.SpanContext.Traceparent())
+	if s.SpanContext.TraceState != "" {
+		header.Set(TracestateHeader, s.SpanContext.TraceState)
+	}
+}
+
+// Finish ends the span and exports it if it is sampled. Only the first call
+// has an effect.
+func (s *Span) Finish() {
+	s.mu.Lock()
+	if s.ended {
+		s.mu.Unlock()
+		return
+	}
+	s.ended = true
+	s.End = time.Now()
+	s.mu.Unlock()
+	if s.exporter != nil && s.SpanContext.IsSampled() {
+		s.exporter.ExportSpan(s)
+	}
+}
+
+// InMemoryExporter keeps the exported spans in memory, for tests.
+type InMemoryExporter struct {
+	mu    sync.Mutex
+	spans []*Span
+}
+
+// ExportSpan implements SpanExporter.
+func (e *InMemoryExporter) ExportSpan(span *Span) {
+	e.mu.Lock()
+	e.spans = append(e.spans, span)
+	e.mu.Unlock()
+}
+
+// Spans returns the exported spans, in the order they ended.
+func (e *InMemoryExporter) Spans() []*Span {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	return append([]*Span(nil), e.spans...)
+}
+
+// Reset drops the exported spans.
+func (e *InMemoryExporter) Reset() {
+	e.mu.Lock()
+	e.spans = nil
+	e.mu.Unlock()
+}
+
+type spanContextKey struct{}
+
+// ContextWithSpan returns a copy of ctx carrying span.
+func ContextWithSpan(ctx context.Context, span *Span) context.Context {
+	return context.WithValue(ctx, spanContextKey{}, span)
+}
+
+// SpanFromContext returns the span carried by ctx, or nil.
+func SpanFromContext(ctx context.Context) *Span {
+	span, _ := ctx.Value(spanContextKey{}).(*Span)
+	return span
+}
+
+// Span returns the span of the request set by the Tracing middleware, or nil.
+func (c *Context) Span() *Span {
+	if v, ok := c.Get(SpanKey); ok {
+		span, _ := v.(*Span)
+		return span
+	}
+	return nil
+}
+
+// TracingConfig configures the Tracing middleware.
+type TracingConfig struct {
+	// Exporter receives the spans of the sampled requests.
+	Exporter SpanExporter
+
+	// ResponseHeader, when true, sends the traceparent of the request span
+	// in the response.
+	ResponseHeader bool
+}
+
+// Tracing returns a middleware creating a span per request, named by the
+// method and the route pattern, e.g. "GET /users/:id". The span continues the
+// trace of the W3C traceparent and tracestate headers of the request when
+// they are valid, and starts a new sampled trace otherwise. It records the
+// status of the response and the errors of Context.Errors.
+//
+// The span is available with Context.Span, and in the context of the request
+// with SpanFromContext, to start child spans or propagate the trace.
+func Tracing(cfg TracingConfig) HandlerFunc {
+	return func(c *Context) {
+		parent, ok := ParseTraceparent(c.requestHeader(TraceparentHeader))
+		if ok {
+			if ts := c.requestHeader(TracestateHeader); len(ts) <= maxTracestateLen {
+				parent.TraceState = ts
+			}
+		}
+
+		name := c.Method
+		if route := c.FullPath(); route != "" {
+			name += " " + route
+		}
+		span := newSpan(name, parent, cfg.Exporter)
+		span.SetAttribute("http.method", c.Method)
+		span.SetAttribute("http.route", c.FullPath())
+		span.SetAttribute("http.target", c.Request.URL.RequestURI())
+		c.Set(SpanKey, span)
+		c.Request = c.Request.WithContext(ContextWithSpan(c.Request.Context(), span))
+		if cfg.ResponseHeader {
+			c.SetHeader(TraceparentHeader, span.SpanContext.Traceparent())
+		}
+
+		c.Next()
+
+		status := c.Writer.Status()
+		span.SetAttribute("http.status_code", status)
+		if id := c.RequestID(); id != "" {
+			span.SetAttribute("http.request_id", id)
+		}
+		for _, err := range c.Errors {
+			span.RecordError(err.Err)
+		}
+		if len(c.Errors) == 0 && status >= http.StatusInternalServerError {
+			span.SetStatus(SpanStatusError, strconv.Itoa(status)+" "+http.StatusText(status))
+		}
+		span.Finish()
+	}
+}
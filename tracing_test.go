@@ -0,0 +1,141 @@
+package rum
+
+import (
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+const testTraceparent = "00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01"
+
+func TestParseTraceparent(t *testing.T) {
+	sc, ok := ParseTraceparent(testTraceparent)
+	assert.True(t, ok)
+	assert.Equal(t, "4bf92f3577b34da6a3ce929d0e0e4736", sc.TraceID.String())
+	assert.Equal(t, "00f067aa0ba902b7", sc.SpanID.String())
+	assert.True(t, sc.IsSampled())
+	assert.True(t, sc.Remote)
+	assert.Equal(t, testTraceparent, sc.Traceparent())
+
+	// a later version may append fields
+	_, ok = ParseTraceparent("01-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-00-extra")
+	assert.True(t, ok)
+
+	for _, s := range []string{
+		"",
+		"00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01-extra",
+		"ff-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01",
+		"00-00000000000000000000000000000000-00f067aa0ba902b7-01",
+		"00-4bf92f3577b34da6a3ce929d0e0e4736-0000000000000000-01",
+		"00-4BF92F3577B34DA6A3CE929D0E0E4736-00f067aa0ba902b7-01",
+		"00-4bf92f3577b34da6a3ce929d0e0e473-600f067aa0ba902b7-01",
+		"00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-0g",
+		"01-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01x",
+	} {
+		_, ok := ParseTraceparent(s)
+		assert.False(t, ok, s)
+	}
+}
+
+func TestTracingContinuesTrace(t *testing.T) {
+	exporter := &InMemoryExporter{}
+	router := New(":9678")
+	router.Use(Tracing(TracingConfig{Exporter: exporter, ResponseHeader: true}))
+	router.GET("/users/:id", func(c *Context) {
+		child := c.Span().StartChild("load user")
+		assert.Equal(t, c.Span(), SpanFromContext(c.Request.Context()))
+
+		outgoing := http.Header{}
+		child.Inject(outgoing)
+		assert.Equal(t, "00-4bf92f3577b34da6a3ce929d0e0e4736-"+child.SpanContext.SpanID.String()+"-01",
+			outgoing.Get(TraceparentHeader))
+		assert.Equal(t, "vendor=value", outgoing.Get(TracestateHeader))
+		child.Finish()
+		child.Finish()
+
+		c.String(http.StatusOK, "ok")
+	})
+
+	w := PerformRequest(router, "GET", "/users/42?x=1",
+		header{TraceparentHeader, testTraceparent},
+		header{TracestateHeader, "vendor=value"})
+
+	spans := exporter.Spans()
+	if assert.Len(t, spans, 2) {
+		child, span := spans[0], spans[1]
+		assert.Equal(t, "GET /users/:id", span.Name)
+		assert.Equal(t, "4bf92f3577b34da6a3ce929d0e0e4736", span.SpanContext.TraceID.String())
+		assert.Equal(t, "00f067aa0ba902b7", span.Parent.SpanID.String())
+		assert.NotEqual(t, span.Parent.SpanID, span.SpanContext.SpanID)
+		assert.Equal(t, "vendor=value", span.SpanContext.TraceState)
+		assert.Equal(t, SpanStatusUnset, span.Status)
+		assert.Equal(t, map[string]interface{}{
+			"http.method":      "GET",
+			"http.route":       "/users/:id",
+			"http.target":      "/users/42?x=1",
+			"http.status_code": http.StatusOK,
+		}, span.Attributes)
+		assert.False(t, span.End.Before(span.Start))
+
+		assert.Equal(t, "load user", child.Name)
+		assert.Equal(t, span.SpanContext.SpanID, child.Parent.SpanID)
+		assert.Equal(t, span.SpanContext.TraceID, child.SpanContext.TraceID)
+
+		assert.Equal(t, span.SpanContext.Traceparent(), w.Header().Get(TraceparentHeader))
+	}
+}
+
+func TestTracingNewTraceAndErrors(t *testing.T) {
+	exporter := &InMemoryExporter{}
+	router := New(":9678")
+	router.Use(Tracing(TracingConfig{Exporter: exporter}))
+	router.GET("/fail", func(c *Context) {
+		c.AbortWithError(http.StatusBadRequest, errors.New("bad id"))
+	})
+	router.GET("/unavailable", func(c *Context) {
+		c.Status(http.StatusServiceUnavailable)
+	})
+
+	w := PerformRequest(router, "GET", "/fail", header{TraceparentHeader, "garbage"})
+	assert.Empty(t, w.Header().Get(TraceparentHeader))
+	PerformRequest(router, "GET", "/unavailable")
+
+	spans := exporter.Spans()
+	if assert.Len(t, spans, 2) {
+		assert.True(t, spans[0].SpanContext.TraceID.IsValid())
+		assert.False(t, spans[0].Parent.IsValid())
+		assert.True(t, spans[0].SpanContext.IsSampled())
+		assert.Equal(t, SpanStatusError, spans[0].Status)
+		assert.Equal(t, "bad id", spans[0].StatusMessage)
+		assert.Len(t, spans[0].Errors, 1)
+
+		assert.Equal(t, SpanStatusError, spans[1].Status)
+		assert.Equal(t, "503 Service Unavailable", spans[1].StatusMessage)
+		assert.NotEqual(t, spans[0].SpanContext.TraceID, spans[1].SpanContext.TraceID)
+	}
+
+	exporter.Reset()
+	assert.Empty(t, exporter.Spans())
+}
+
+func TestTracingNotSampled(t *testing.T) {
+	exporter := &InMemoryExporter{}
+	router := New(":9678")
+	router.Use(Tracing(TracingConfig{Exporter: exporter}))
+	router.GET("/", func(c *Context) {
+		assert.False(t, c.Span().SpanContext.IsSampled())
+	})
+
+	PerformRequest(router, "GET", "/", header{TraceparentHeader, "00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-00"})
+	assert.Empty(t, exporter.Spans())
+}
+
+func TestContextSpanWithoutTracing(t *testing.T) {
+	c, _ := CreateTestContext(httptest.NewRecorder())
+	assert.Nil(t, c.Span())
+	c.Request = httptest.NewRequest("GET", "/", nil)
+	assert.Nil(t, SpanFromContext(c.Request.Context()))
+}
@@ -16,6 +16,8 @@ type node struct {
 	child        []*node
 	hasWildChild bool
 	handlers     HandlersChain
+	// fullPath is the path the handlers were registered with, e.g. /users/:id
+	fullPath string
 }
 
 func findWildcard(path string) (wildcard string, i int, valid bool) {
@@ -96,12 +98,14 @@ func (n *node) splitOrMakeNode(path string, fullPath string, handlers HandlersCh
 			child:        n.child,
 			handlers:     n.handlers,
 			idxcs:        n.idxcs,
+			fullPath:     n.fullPath,
 		}
 		n.child = []*node{&child}
 		n.idxcs = string([]byte{n.path[inx]})
 		n.path = n.path[:inx]
 		n.hasWildChild = false
 		n.handlers = nil
+		n.fullPath = ""
 	}
 
 	if inx < len(path) {
@@ -136,7 +140,7 @@ func (n *node) splitOrMakeNode(path string, fullPath string, handlers HandlersCh
 			if len(path) >= len(n.path) && n.path == path[:len(n.path)] &&
 				n.nType != catchAll &&
 				(len(n.path) == len(path) || path[len(n.path)] == '/') {
-				n.addRoute(path, handlers)
+				n.splitOrMakeNode(path, fullPath, handlers)
 				return
 			} else {
 				panic("wildcar conflict! fullpath is '" + fullPath + "' node path is '" + n.path + "' subpath is '" + path + "'")
@@ -150,6 +154,7 @@ func (n *node) splitOrMakeNode(path string, fullPath string, handlers HandlersCh
 		panic("handlers are already registered for path '" + fullPath + "'")
 	}
 	n.handlers = handlers
+	n.fullPath = fullPath
 }
 
 func (n *node) insertChild(path, fullPath string, handlers HandlersChain) {
@@ -198,6 +203,7 @@ func (n *node) insertChild(path, fullPath string, handlers HandlersChain) {
 			}
 
 			n.handlers = handlers
+			n.fullPath = fullPath
 			return
 		}
 
@@ -232,6 +238,7 @@ func (n *node) insertChild(path, fullPath string, handlers HandlersChain) {
 			path:     path[i:],
 			nType:    catchAll,
 			handlers: handlers,
+			fullPath: fullPath,
 		}
 		n.child = []*node{child}
 
@@ -241,6 +248,7 @@ func (n *node) insertChild(path, fullPath string, handlers HandlersChain) {
 
 	n.path = path
 	n.handlers = handlers
+	n.fullPath = fullPath
 }
 
 func (n *node) saveParam(params *Params, ps *Params, key, value string) {
@@ -254,7 +262,9 @@ func (n *node) saveParam(params *Params, ps *Params, key, value string) {
 	}
 }
 
-func (n *node) getValue(path string, params *Params) (handlers HandlersChain, ps *Params) {
+// getValue returns the handlers registered for path, its params and the path
+// the handlers were registered with.
+func (n *node) getValue(path string, params *Params) (handlers HandlersChain, ps *Params, fullPath string) {
 	for {
 		prefix := n.path
 		if len(path) > len(prefix) && path[:len(prefix)] == prefix {
@@ -311,17 +321,17 @@ func (n *node) getValue(path string, params *Params) (handlers HandlersChain, ps
 				panic("invaild node type")
 			}
 
-			handlers = n.handlers
+			handlers, fullPath = n.handlers, n.fullPath
 			break
 		}
 
 		if path == prefix {
-			handlers = n.handlers
+			handlers, fullPath = n.handlers, n.fullPath
 			break
 		}
 
 		// Nothing found.
 		break
 	}
-	return handlers, ps
+	return handlers, ps, fullPath
 }
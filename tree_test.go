@@ -32,7 +32,7 @@ func fakeHandler(val string) HandlersChain {
 
 func checkRequests(t *testing.T, tree *node, requests testRequests) {
 	for _, request := range requests {
-		handlers, psp := tree.getValue(request.path, getParams())
+		handlers, psp, fullPath := tree.getValue(request.path, getParams())
 
 		if handlers == nil {
 			if !request.nilHandler {
@@ -45,6 +45,9 @@ func checkRequests(t *testing.T, tree *node, requests testRequests) {
 			if fakeHandlerValue != request.route {
 				t.Errorf("handle mismatch for route '%s': Wrong handle (%s != %s)", request.path, fakeHandlerValue, request.route)
 			}
+			if fullPath != request.route {
+				t.Errorf("full path mismatch for route '%s': Wrong full path (%s != %s)", request.path, fullPath, request.route)
+			}
 		}
 
 		var ps Params
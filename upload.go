@@ -0,0 +1,140 @@
+package rum
+
+import (
+	"errors"
+	"hash"
+	"io"
+	"io/ioutil"
+	"mime/multipart"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// ErrUnsafePath is returned when the destination of an uploaded file is
+// outside of SaveOptions.Root.
+var ErrUnsafePath = errors.New("rum: destination escapes the upload root")
+
+// SaveOptions configures Context.SaveUploadedFileWith.
+type SaveOptions struct {
+	// Root, when set, is the directory dst is relative to. A dst resolving
+	// outside of Root, e.g. "../secret", is rejected with ErrUnsafePath.
+	Root string
+
+	// DirPerm, when not 0, creates the missing parent directories of dst
+	// with these permissions (before umask).
+	DirPerm os.FileMode
+
+	// FilePerm is the permission of the saved file, 0666 (before umask) when
+	// 0. An atomic write keeps the 0600 permission of its temporary file
+	// when FilePerm is 0.
+	FilePerm os.FileMode
+
+	// Atomic writes the file to a temporary file next to dst, renamed to dst
+	// once complete, so that dst is never seen partially written.
+	Atomic bool
+
+	// Hash, when set, is fed with the content of the file while it is saved,
+	// e.g. sha256.New(). Its checksum is read with Hash.Sum(nil).
+	Hash hash.Hash
+}
+
+// SanitizeFilename returns a name safe to use as the base name of a file
+// from a name chosen by a client, like multipart.FileHeader.Filename. It
+// drops any directory, including Windows ones, control and reserved
+// characters and leading dots, so that the result can not traverse or hide
+// a path. It returns "file" when nothing is left.
+func SanitizeFilename(name string) string {
+	name = strings.ReplaceAll(name, `\`, "/")
+	if i := strings.LastIndexByte(name, '/'); i >= 0 {
+		name = name[i+1:]
+	}
+	name = strings.Map(func(r rune) rune {
+		if r < 0x20 || r == 0x7f || strings.ContainsRune(`<>:"|?*`, r) {
+			return -1
+		}
+		return r
+	}, name)
+	name = strings.TrimLeft(name, ". ")
+	name = strings.TrimRight(name, ". ")
+	if name == "" {
+		return "file"
+	}
+	return name
+}
+
+// SaveUploadedFileWith saves the uploaded file to dst as configured by opts.
+func (c *Context) SaveUploadedFileWith(file *multipart.FileHeader, dst string, opts SaveOptions) error {
+	if opts.Root != "" {
+		root := filepath.Clean(opts.Root)
+		dst = filepath.Join(root, dst)
+		rel, err := filepath.Rel(root, dst)
+		if err != nil || rel == "." || rel == ".." || strings.HasPrefix(rel, ".."+string(filepath.Separator)) {
+			return ErrUnsafePath
+		}
+	}
+	if opts.DirPerm != 0 {
+		if err := os.MkdirAll(filepath.Dir(dst), opts.DirPerm); err != nil {
+			return err
+		}
+	}
+
+	src, err := file.Open()
+	if err != nil {
+		return err
+	}
+	defer src.Close()
+
+	var r io.Reader = src
+	if opts.Hash != nil {
+		r = io.TeeReader(src, opts.Hash)
+	}
+	if opts.Atomic {
+		return saveAtomic(r, dst, opts.FilePerm)
+	}
+
+	perm := opts.FilePerm
+	if perm == 0 {
+		perm = 0666
+	}
+	out, err := os.OpenFile(dst, os.O_WRONLY|os.O_CREATE|os.O_TRUNC, perm)
+	if err != nil {
+		return err
+	}
+	if _, err = io.Copy(out, r); err != nil {
+		out.Close()
+		return err
+	}
+	return out.Close()
+}
+
+// saveAtomic writes r to a temporary file in the directory of dst and renames
+// it to dst. The temporary file is removed on failure.
+func saveAtomic(r io.Reader, dst string, perm os.FileMode) (err error) {
+	tmp, err := ioutil.TempFile(filepath.Dir(dst), "."+filepath.Base(dst)+".*.tmp")
+	if err != nil {
+		return err
+	}
+	defer func() {
+		if err != nil {
+			tmp.Close()
+			os.Remove(tmp.Name())
+		}
+	}()
+
+	if _, err = io.Copy(tmp, r); err != nil {
+		return err
+	}
+	if err = tmp.Sync(); err != nil {
+		return err
+	}
+	if perm != 0 {
+		if err = tmp.Chmod(perm); err != nil {
+			return err
+		}
+	}
+	if err = tmp.Close(); err != nil {
+		return err
+	}
+	return os.Rename(tmp.Name(), dst)
+}
@@ -0,0 +1,91 @@
+package rum
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"io/ioutil"
+	"mime/multipart"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func uploadedFile(t *testing.T, name, content string) (*Context, *multipart.FileHeader) {
+	c := multipartContext(t, func(mw *multipart.Writer) {
+		w, _ := mw.CreateFormFile("file", name)
+		w.Write([]byte(content))
+	})
+	fh, err := c.FormFile("file")
+	assert.NoError(t, err)
+	return c, fh
+}
+
+func TestSanitizeFilename(t *testing.T) {
+	for name, want := range map[string]string{
+		"photo.png":              "photo.png",
+		"../../etc/passwd":       "passwd",
+		`..\..\windows\win.ini`:  "win.ini",
+		".htaccess":              "htaccess",
+		"a<b>c:d\"e|f?g*h.txt":   "abcdefgh.txt",
+		"line\nbreak.txt":        "linebreak.txt",
+		"..":                     "file",
+		"":                       "file",
+		"dir/":                   "file",
+		"  report 2024.pdf . . ": "report 2024.pdf",
+	} {
+		assert.Equal(t, want, SanitizeFilename(name), name)
+	}
+}
+
+func TestSaveUploadedFileWith(t *testing.T) {
+	root := t.TempDir()
+	c, fh := uploadedFile(t, "../../evil.txt", "hello")
+
+	h := sha256.New()
+	err := c.SaveUploadedFileWith(fh, filepath.Join("a", "b", SanitizeFilename(fh.Filename)), SaveOptions{
+		Root:     root,
+		DirPerm:  0750,
+		FilePerm: 0640,
+		Atomic:   true,
+		Hash:     h,
+	})
+	assert.NoError(t, err)
+
+	dst := filepath.Join(root, "a", "b", "evil.txt")
+	data, err := ioutil.ReadFile(dst)
+	assert.NoError(t, err)
+	assert.Equal(t, "hello", string(data))
+	sum := sha256.Sum256([]byte("hello"))
+	assert.Equal(t, hex.EncodeToString(sum[:]), hex.EncodeToString(h.Sum(nil)))
+	info, err := os.Stat(dst)
+	assert.NoError(t, err)
+	assert.Equal(t, os.FileMode(0640), info.Mode().Perm())
+
+	// no temporary file is left behind
+	entries, _ := ioutil.ReadDir(filepath.Dir(dst))
+	assert.Len(t, entries, 1)
+}
+
+func TestSaveUploadedFileWithUnsafePath(t *testing.T) {
+	root := t.TempDir()
+	c, fh := uploadedFile(t, "a.txt", "hello")
+
+	for _, dst := range []string{"../a.txt", "b/../../a.txt", ".", ""} {
+		assert.Equal(t, ErrUnsafePath, c.SaveUploadedFileWith(fh, dst, SaveOptions{Root: root}), dst)
+	}
+	assert.NoError(t, c.SaveUploadedFileWith(fh, "b/../a.txt", SaveOptions{Root: root}))
+	_, err := os.Stat(filepath.Join(root, "a.txt"))
+	assert.NoError(t, err)
+}
+
+func TestSaveUploadedFileWithMissingDir(t *testing.T) {
+	root := t.TempDir()
+	c, fh := uploadedFile(t, "a.txt", "hello")
+
+	dst := filepath.Join(root, "missing", "a.txt")
+	assert.Error(t, c.SaveUploadedFileWith(fh, dst, SaveOptions{}))
+	assert.Error(t, c.SaveUploadedFileWith(fh, dst, SaveOptions{Atomic: true}))
+	assert.NoError(t, c.SaveUploadedFileWith(fh, dst, SaveOptions{DirPerm: 0755}))
+}
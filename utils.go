@@ -1,5 +1,15 @@
 package rum
 
+import (
+	"errors"
+	"net/http"
+	"reflect"
+	"runtime"
+	"strconv"
+	"strings"
+	"unicode"
+)
+
 func assert1(guard bool, text string) {
 	if !guard {
 		panic(text)
@@ -22,6 +32,10 @@ func joinPath(absolutePath, relativePath string) string {
 	return string(out)
 }
 
+func nameOfFunction(f interface{}) string {
+	return runtime.FuncForPC(reflect.ValueOf(f).Pointer()).Name()
+}
+
 func filterFlags(content string) string {
 	for i, char := range content {
 		if char == ' ' || char == ';' {
@@ -30,3 +44,156 @@ func filterFlags(content string) string {
 	}
 	return content
 }
+
+// contentDisposition builds a Content-Disposition header value as described in
+// RFC 6266. Non-ASCII filenames are sent with the RFC 5987 filename* parameter
+// alongside a plain ASCII fallback for older clients.
+func contentDisposition(dispositionType, filename string) string {
+	if isASCII(filename) {
+		return dispositionType + `; filename="` + quoteEscaper.Replace(filename) + `"`
+	}
+	return dispositionType + `; filename="` + quoteEscaper.Replace(asciiFallback(filename)) +
+		`"; filename*=UTF-8''` + encodeExtValue(filename)
+}
+
+var quoteEscaper = strings.NewReplacer("\\", "\\\\", `"`, "\\\"")
+
+func isASCII(s string) bool {
+	for i := 0; i < len(s); i++ {
+		if s[i] < 0x20 || s[i] > unicode.MaxASCII {
+			return false
+		}
+	}
+	return true
+}
+
+func asciiFallback(s string) string {
+	var b strings.Builder
+	for _, r := range s {
+		if r < 0x20 || r > unicode.MaxASCII {
+			r = '_'
+		}
+		b.WriteRune(r)
+	}
+	return b.String()
+}
+
+// encodeExtValue percent-encodes every byte of s that is not an attr-char
+// according to RFC 5987.
+func encodeExtValue(s string) string {
+	const hex = "0123456789ABCDEF"
+	var b strings.Builder
+	for i := 0; i < len(s); i++ {
+		c := s[i]
+		if isAttrChar(c) {
+			b.WriteByte(c)
+			continue
+		}
+		b.WriteByte('%')
+		b.WriteByte(hex[c>>4])
+		b.WriteByte(hex[c&0x0f])
+	}
+	return b.String()
+}
+
+func isAttrChar(c byte) bool {
+	switch {
+	case 'a' <= c && c <= 'z', 'A' <= c && c <= 'Z', '0' <= c && c <= '9':
+		return true
+	}
+	return strings.IndexByte("!#$&+-.^_`|~", c) >= 0
+}
+
+// httpRange specifies the byte range to be sent to the client.
+type httpRange struct {
+	start, length int64
+}
+
+func (r httpRange) contentRange(size int64) string {
+	return "bytes " + strconv.FormatInt(r.start, 10) + "-" +
+		strconv.FormatInt(r.start+r.length-1, 10) + "/" + strconv.FormatInt(size, 10)
+}
+
+var errRangeUnsatisfiable = errors.New("invalid range: failed to overlap")
+
+// parseRange parses a Range header string as per RFC 7233 for a body of the
+// given size. Only a single range is supported; it returns nil without error
+// when the header is malformed or asks for several ranges, in which case the
+// header should be ignored and the whole body sent.
+func parseRange(s string, size int64) (*httpRange, error) {
+	const b = "bytes="
+	if !strings.HasPrefix(s, b) {
+		return nil, nil
+	}
+	spec := strings.TrimSpace(s[len(b):])
+	if spec == "" || strings.Contains(spec, ",") {
+		return nil, nil
+	}
+	i := strings.Index(spec, "-")
+	if i < 0 {
+		return nil, nil
+	}
+	start, end := strings.TrimSpace(spec[:i]), strings.TrimSpace(spec[i+1:])
+
+	var r httpRange
+	if start == "" {
+		// suffix-byte-range-spec: the last N bytes of the body.
+		n, err := strconv.ParseInt(end, 10, 64)
+		if err != nil || n < 0 {
+			return nil, nil
+		}
+		if n == 0 || size == 0 {
+			return nil, errRangeUnsatisfiable
+		}
+		if n > size {
+			n = size
+		}
+		r.start = size - n
+		r.length = n
+		return &r, nil
+	}
+
+	first, err := strconv.ParseInt(start, 10, 64)
+	if err != nil || first < 0 {
+		return nil, nil
+	}
+	if first >= size {
+		return nil, errRangeUnsatisfiable
+	}
+	r.start = first
+	if end == "" {
+		r.length = size - first
+		return &r, nil
+	}
+	last, err := strconv.ParseInt(end, 10, 64)
+	if err != nil || last < first {
+		return nil, nil
+	}
+	if last >= size {
+		last = size - 1
+	}
+	r.length = last - first + 1
+	return &r, nil
+}
+
+// ifRangeMatches reports whether the If-Range precondition ir holds for a
+// response carrying the given headers. An empty If-Range always matches.
+func ifRangeMatches(ir string, h http.Header) bool {
+	if ir == "" {
+		return true
+	}
+	if strings.HasPrefix(ir, `"`) || strings.HasPrefix(ir, "W/") {
+		// If-Range requires a strong comparison.
+		etag := h.Get("ETag")
+		return !strings.HasPrefix(ir, "W/") && etag != "" && etag == ir
+	}
+	t, err := http.ParseTime(ir)
+	if err != nil {
+		return false
+	}
+	lm, err := http.ParseTime(h.Get("Last-Modified"))
+	if err != nil {
+		return false
+	}
+	return t.Equal(lm)
+}